@@ -21,6 +21,7 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"os"
@@ -36,17 +37,40 @@ func newAddCmd() *cobra.Command {
 		Use:   "add [filename]",
 		Short: "create newfile of source code",
 		Long:  `This command create new file of source code using specified license`,
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			//cmd.Printf("add %s\r\n", args)
-			config, license, author, LIsNotSet := ProcessArg(cmd, args)
+			config, license, author, LIsNotSet, err := ProcessArg(cmd, args)
+			if err != nil {
+				return err
+			}
+			ho, err := getHeaderOptions(cmd, author, config)
+			if err != nil {
+				return err
+			}
 			packageName, _ := cmd.Flags().GetString("package")
 			//fmt.Printf("packageName:[%s]\r\n", packageName)
 			input := cmd.Flags().Args()
 
+			rp, err := newReporter(cmd, "add")
+			if err != nil {
+				return err
+			}
+			j, err := NewJournal("add")
+			if err != nil {
+				return fmt.Errorf("cannot create journal: %w", err)
+			}
 			//fmt.Println(license)
 			for _, fileName := range input {
-				createNew(fileName, license, author, packageName, cmd.OutOrStdout(), LIsNotSet, config)
+				rp.report(createNew(fileName, license, ho, packageName, cmd.OutOrStderr(), LIsNotSet, config, j))
+			}
+			err = rp.finish()
+			if cerr := j.Close(); err == nil {
+				err = cerr
 			}
+			if err != nil {
+				return err
+			}
+			return rp.err()
 		},
 	}
 
@@ -55,70 +79,66 @@ func newAddCmd() *cobra.Command {
 	return addCmd
 }
 
-func createNew(fn string, l *tools.License, author, packageName string, messageWriter io.Writer, LicenseIsNotSet bool, config *Config) {
+//createNew creates new source file fn with license header and returns report of it. Progress messages are written to messageWriter.
+func createNew(fn string, l *tools.License, ho *tools.HeaderOptions, packageName string, messageWriter io.Writer, LicenseIsNotSet bool, config *Config, j *Journal) *FileReport {
 	isFilePath, err := tools.IsFilePath(fn)
 	//fmt.Fprintf(messageWriter, "l:H-[%s],T-[%s]\r\n", l.Header, l.Text)
-	if isFilePath {
-		fp, err := filepath.Abs(fn)
-		if err != nil {
-			panic(err)
-		}
-		isExist, err := tools.IsExistFile(fp)
-		//fmt.Fprintf(messageWriter, "fp[%s]\r\n", fp)
-		if isExist {
-			fmt.Fprintf(messageWriter, "%s is exist. %s not created.\r\n", fp, fp)
-			return
-		}
+	if !isFilePath {
+		return newFileReport(fn, ActionFailed, nil).fail(err)
+	}
 
-		if err != nil {
-			fmt.Fprintf(messageWriter, err.Error())
-			fmt.Fprintln(messageWriter)
-			fmt.Fprintf(messageWriter, "%s not created.\r\n", fp)
-			return
-		}
-		//fmt.Fprintf(messageWriter, "fp[%s]\r\n", fp)
-		dir := filepath.Dir(fp)
-		pn := packageName
-		if pn == "" {
-			//fmt.Fprintf(messageWriter, "base:[%s]\r\n", filepath.Base(dir))
-			pn = filepath.Base(dir)
-			//fmt.Fprintf(messageWriter, "pn:[%s]\r\n", pn)
-		}
+	fp, err := filepath.Abs(fn)
+	if err != nil {
+		return newFileReport(fn, ActionFailed, nil).fail(err)
+	}
+	r := newFileReport(fp, ActionCreated, nil)
+	isExist, err := tools.IsExistFile(fp)
+	//fmt.Fprintf(messageWriter, "fp[%s]\r\n", fp)
+	if isExist {
+		return r.skip("file already exists")
+	}
+	if err != nil {
+		return r.fail(err)
+	}
 
-		license := l
-		if isExistDir(dir) {
-			if LicenseIsNotSet && config.License["fix"] == "" {
-				ld := tools.GetDirLicense(dir)
-				if ld != nil {
-					fmt.Printf("In %s, license file detected. License: %s", dir, ld.Name)
-					license = ld
-				}
-			}
-		} else {
-			fmt.Fprintf(messageWriter, "make dir %s\r\n", dir)
-			fmt.Printf("Making directry: %s\r\n", dir)
-			err := os.MkdirAll(dir, 0755)
-			if err != nil {
-				panic(err)
+	dir := filepath.Dir(fp)
+	pn := packageName
+	if pn == "" {
+		pn = filepath.Base(dir)
+	}
+
+	license := l
+	if isExistDir(dir) {
+		if LicenseIsNotSet && config.License["fix"] == "" {
+			ld := tools.GetDirLicense(dir)
+			if ld != nil {
+				fmt.Fprintf(messageWriter, "In %s, license file detected. License: %s\r\n", dir, ld.Name)
+				license = ld
 			}
-			fmt.Printf("%s is made.\r\n", dir)
 		}
-
-		f, err := os.Create(fp)
+	} else {
+		fmt.Fprintf(messageWriter, "Making directry: %s\r\n", dir)
+		err := os.MkdirAll(dir, 0755)
 		if err != nil {
-			fmt.Fprintln(messageWriter, err)
-		} else {
-			defer f.Close()
-			fmt.Fprintf(messageWriter, "begin create: %s\r\n", fp)
-			license.WriteLicenseHeader(f, author)
-			fmt.Fprintln(f, "")
-			//fmt.Println("pn:[", pn, "]")
-			fmt.Fprintln(f, "package", pn)
-			fmt.Fprintf(messageWriter, "created: %s\r\n", fp)
+			return r.fail(&tools.IOError{Op: "make directory", Path: dir, Err: err})
 		}
-	} else {
-		fmt.Println(err)
+		fmt.Fprintf(messageWriter, "%s is made.\r\n", dir)
+	}
+
+	fmt.Fprintf(messageWriter, "begin create: %s\r\n", fp)
+	var f bytes.Buffer
+	err = license.WriteLicenseHeader(&f, ho.ForFile(fp))
+	if err != nil {
+		return r.fail(err)
+	}
+	fmt.Fprintln(&f, "")
+	fmt.Fprintln(&f, "package", pn)
+	err = j.WriteFile(fp, f.Bytes(), nil)
+	if err != nil {
+		return r.fail(err)
 	}
+	r.setNew(license, f.Bytes())
+	return r
 }
 
 func isExistDir(dir string) bool {
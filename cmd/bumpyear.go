@@ -0,0 +1,147 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+// newBumpYearCmd represents the bump-year command
+func newBumpYearCmd() *cobra.Command {
+	bumpYearCmd := &cobra.Command{
+		Use:   "bump-year [Paths of files or directories]",
+		Short: "update copyright year of license header of .go files in input directory or specified files.",
+		Long: `liquid bump-year extends copyright years of existing license header to the current year (or year flag), like "2018" to "2018-2019". License text and the other parts of files are kept.
+If author flag is specified, only headers whose copyright holders include the author are updated. The run can be undone by liquid undo.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			year, err := getYear(cmd)
+			if err != nil {
+				return err
+			}
+			holder := ""
+			if cmd.Flags().Changed("author") {
+				holder, err = cmd.Flags().GetString("author")
+				if err != nil {
+					panic(err)
+				}
+			}
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				panic(err)
+			}
+			keepMtime, err := cmd.Flags().GetBool("keep-mtime")
+			if err != nil {
+				panic(err)
+			}
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				panic(err)
+			}
+			rp, err := newReporter(cmd, "bump-year")
+			if err != nil {
+				return err
+			}
+
+			targets, errs, err := resolveTargets(cmd, &licenseCache{})
+			if err != nil {
+				return err
+			}
+			for _, err := range errs {
+				cmd.Println(err)
+				rp.fault(err)
+			}
+			if !force {
+				for _, err := range skipDirtyTargets(targets) {
+					cmd.Println(err)
+					rp.fault(err)
+				}
+			}
+
+			j, err := NewJournal("bump-year")
+			if err != nil {
+				return fmt.Errorf("cannot create journal: %w", err)
+			}
+			opt := &tools.WriteOption{KeepModTime: keepMtime}
+			reports := make([]*FileReport, len(targets))
+			parallel(jobs, len(targets), func(i int) {
+				t := targets[i]
+				if t.skip != "" {
+					reports[i] = newFileReport(t.path, ActionSkipped, nil).skip(t.skip)
+					return
+				}
+				reports[i] = bumpFileYear(&t, year, holder, opt, j)
+			})
+			for _, r := range reports {
+				rp.report(r)
+			}
+			err = rp.finish()
+			if cerr := j.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+			return rp.err()
+		},
+	}
+
+	bumpYearCmd.Flags().Bool("force", false, "If this flag is true, files that have uncommitted changes in git work tree are also modified.")
+	bumpYearCmd.Flags().Bool("keep-mtime", false, "If this flag is true, modification time of rewritten files is kept.")
+	addTargetFlags(bumpYearCmd, false)
+
+	return bumpYearCmd
+}
+
+//bumpFileYear extends copyright years of license header of t to year. If holder is not empty, only header whose copyright holders include holder is updated.
+//The file is rewritten atomically according to opt, and its original content is recorded to j.
+func bumpFileYear(t *headTarget, year int, holder string, opt *tools.WriteOption, j *Journal) *FileReport {
+	fp := t.path
+	src, err := t.read()
+	if err != nil {
+		return newFileReport(fp, ActionFailed, nil).fail(err)
+	}
+	r := newFileReport(fp, ActionUpdated, src)
+	if r.header == nil {
+		return r.skip("no license header")
+	}
+	if ok, reason := (&stripFilter{holder: normalizeHolder(holder)}).match(r.header); !ok {
+		return r.skip(reason)
+	}
+
+	data := tools.BumpCopyrightYear(src, year)
+	if bytes.Equal(src, data) {
+		r.Action = ActionUnchanged
+		return r
+	}
+	err = j.WriteFile(fp, data, opt)
+	if err != nil {
+		return r.fail(err)
+	}
+	if info := tools.ParseHeader(data); info != nil {
+		r.NewHolders = splitHolders(info.Holder)
+		r.NewYears = info.Years
+	}
+	return r
+}
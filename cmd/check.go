@@ -0,0 +1,145 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+// newCheckCmd represents the check command
+func newCheckCmd() *cobra.Command {
+	checkCmd := &cobra.Command{
+		Use:   "check [Paths of files or directories]",
+		Short: "check license header of .go files in input directory or specified files.",
+		Long: `liquid check reports .go files whose license header is missing, is not specified license, has old copyright year, has placeholder copyright holder or belongs to other copyright holder. liquid check does not modify any file, and exits with non-zero status if some files have problems.
+In addition to common formats, check supports sarif format for code scanning tools and junit format for CI.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, license, author, LIsNotSet, err := ProcessArg(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			ho, err := getHeaderOptions(cmd, author, config)
+			if err != nil {
+				return err
+			}
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				panic(err)
+			}
+
+			rp, err := newReporter(cmd, "check", "sarif", "junit")
+			if err != nil {
+				return err
+			}
+			rp.junitGroup, err = cmd.Flags().GetString("junit-suite")
+			if err != nil {
+				panic(err)
+			}
+			if rp.junitGroup != "dir" && rp.junitGroup != "module" {
+				return usageErrorf("junit-suite must be dir or module")
+			}
+
+			lc := newLicenseCache(license, LIsNotSet, config)
+			targets, errs, err := resolveTargets(cmd, lc)
+			if err != nil {
+				return err
+			}
+			for _, err := range errs {
+				cmd.Println(err)
+				rp.fault(err)
+			}
+
+			for _, r := range runCheckJobs(targets, jobs, ho) {
+				rp.report(r)
+			}
+			err = rp.finish()
+			if err != nil {
+				return err
+			}
+			if err := rp.err(); err != nil {
+				return err
+			}
+			if v := rp.violations(); v > 0 {
+				return &ViolationError{v}
+			}
+			return nil
+		},
+	}
+
+	addTargetFlags(checkCmd, false)
+	checkCmd.Flags().String("junit-suite", "dir", "how files are grouped into test suites in junit format: dir or module")
+
+	return checkCmd
+}
+
+//CheckFileHeader checks license header of the file fp with expected license l, and copyright holder and year of ho.
+func CheckFileHeader(fp string, l *tools.License, ho *tools.HeaderOptions) (tools.HeaderStatus, *tools.HeaderInfo, error) {
+	t := fileTarget(fp, nil, l)
+	return checkTargetHeader(&t, ho)
+}
+
+//checkTargetHeader checks license header of t like CheckFileHeader. Content of t is read from its file system.
+func checkTargetHeader(t *headTarget, ho *tools.HeaderOptions) (tools.HeaderStatus, *tools.HeaderInfo, error) {
+	l := t.license
+	src, err := t.read()
+	if err != nil {
+		return tools.HeaderMissing, nil, err
+	}
+	status, info := tools.CheckHeader(src, l, ho)
+	return status, info, nil
+}
+
+//runCheckJobs checks license header of targets with n workers. Reports are returned in the same order as targets.
+func runCheckJobs(targets []headTarget, n int, ho *tools.HeaderOptions) []*FileReport {
+	reports := make([]*FileReport, len(targets))
+	parallel(n, len(targets), func(i int) {
+		t := targets[i]
+		if t.skip != "" {
+			reports[i] = newFileReport(t.path, ActionSkipped, nil).skip(t.skip)
+			return
+		}
+		fho := ho.ForFile(t.path)
+		status, info, err := checkTargetHeader(&t, fho)
+		r := newFileReport(t.path, ActionChecked, nil)
+		if err != nil {
+			reports[i] = r.fail(err)
+			return
+		}
+		r.setOld(info)
+		r.setStatus(status)
+		r.NewLicense = licenseName(t.license)
+		r.NewHolders = splitHolders(ho.Author)
+		if status != tools.HeaderCorrect {
+			var b bytes.Buffer
+			if err := t.license.WriteLicenseHeader(&b, fho.Inherit(info, t.license)); err == nil {
+				r.expected = b.String()
+			}
+		}
+		reports[i] = r
+	})
+	return reports
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ioutil.WriteFile(filepath.Join(dir, "missing.go"), []byte("package a\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "foreign.go"), []byte("// Copyright (c) 2019 someone\n// other license\n\npackage a\n"), 0644)
+
+	run := func(format string) []byte {
+		command := "liquid check " + dir + " -l mit -a author --format " + format
+		args := strings.Split(command, " ")
+		bout := new(bytes.Buffer)
+		lcmd := newRootCmd()
+		lcmd.SetArgs(args[1:])
+		lcmd.SetOut(bout)
+		lcmd.SetErr(ioutil.Discard)
+		err := lcmd.Execute()
+		if err == nil {
+			t.Error("check must fail if files have problems")
+		}
+		//messages of reading config are written before report.
+		out := bout.Bytes()
+		if i := bytes.IndexAny(out, "{<"); i > 0 {
+			out = out[i:]
+		}
+		return out
+	}
+
+	var sarif sarifLog
+	if err := json.Unmarshal(run("sarif"), &sarif); err != nil {
+		t.Fatal(err)
+	}
+	results := sarif.Runs[0].Results
+	if len(results) != 2 || results[0].RuleID != "foreign" || results[1].RuleID != "missing" {
+		t.Errorf("unexpected sarif results: %+v", results)
+	}
+	if r := results[0].Locations[0].PhysicalLocation.Region; r == nil || r.StartLine != 1 || r.EndLine != 2 {
+		t.Errorf("unexpected region of header: %+v", r)
+	}
+
+	var junit junitTestSuites
+	if err := xml.Unmarshal(run("junit"), &junit); err != nil {
+		t.Fatal(err)
+	}
+	if junit.Tests != 2 || junit.Failures != 2 || len(junit.Suites) != 1 {
+		t.Errorf("unexpected junit report: %+v", junit)
+	}
+}
+
+func TestCheckFaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-check")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "a.go")
+	none := filepath.Join(dir, "nothere.go")
+	ioutil.WriteFile(a, []byte("package a\n"), 0644)
+
+	bout := new(bytes.Buffer)
+	lcmd := newRootCmd()
+	lcmd.SetArgs([]string{"check", a, none, "-f", "-l", "mit", "-a", "author", "--format", "json"})
+	lcmd.SetOut(bout)
+	lcmd.SetErr(ioutil.Discard)
+	if err := lcmd.Execute(); ExitCode(err) == 0 {
+		t.Error("check must fail if input cannot be read")
+	}
+	out := bout.Bytes()
+	if i := bytes.IndexByte(out, '{'); i > 0 {
+		out = out[i:]
+	}
+
+	var report struct {
+		Files   []*FileReport `json:"files"`
+		Summary *Summary      `json:"summary"`
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		t.Fatal(err)
+	}
+	var failed *FileReport
+	for _, r := range report.Files {
+		if r.Path == none {
+			failed = r
+		}
+	}
+	if failed == nil || failed.Action != ActionFailed || failed.Error == "" {
+		t.Errorf("input that cannot be read must be reported as failed with error: %+v", report.Files)
+	}
+	if s := report.Summary; s.Files != 2 || s.Actions[ActionFailed] != 1 {
+		t.Errorf("failed input must be counted in summary: %+v", s)
+	}
+}
+
+func TestCheckJUnitSuite(t *testing.T) {
+	lcmd := newRootCmd()
+	lcmd.SetArgs([]string{"check", "-l", "mit", "-a", "author", "--format", "junit", "--junit-suite", "package"})
+	lcmd.SetOut(ioutil.Discard)
+	lcmd.SetErr(ioutil.Discard)
+	if err := lcmd.Execute(); ExitCode(err) != ExitUsage {
+		t.Errorf("unknown junit-suite must be usage error: %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+//Exit codes of liquid.
+const (
+	ExitOK = 0
+	//ExitFailure is exit code of errors that are not classified.
+	ExitFailure = 1
+	//ExitUsage is exit code of wrong flags or arguments.
+	ExitUsage = 2
+	//ExitIO is exit code of failure in reading or writing files.
+	ExitIO = 3
+	//ExitViolation is exit code of check that found files with problems in license header.
+	ExitViolation = 4
+	//ExitPartial is exit code of runs in which some of files failed to be processed.
+	ExitPartial = 5
+)
+
+//UsageError is error caused by wrong flags or arguments.
+type UsageError struct {
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+//Unwrap returns the underlying error.
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+func usageErrorf(format string, a ...interface{}) error {
+	return &UsageError{fmt.Errorf(format, a...)}
+}
+
+//usageArgs wraps validator of positional arguments so that its error is UsageError.
+func usageArgs(v cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(c *cobra.Command, args []string) error {
+		if err := v(c, args); err != nil {
+			return &UsageError{err}
+		}
+		return nil
+	}
+}
+
+//ViolationError is error that check found files with problems in license header.
+type ViolationError struct {
+	Files int
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("%d files have problems in license header", e.Files)
+}
+
+//PartialError is error that some of files failed to be processed.
+type PartialError struct {
+	Failed int
+	Total  int
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%d of %d files failed", e.Failed, e.Total)
+}
+
+//ExitCode returns exit code of liquid for err.
+func ExitCode(err error) int {
+	var ue *UsageError
+	var le *tools.UnknownLicenseError
+	var ve *ViolationError
+	var pe *PartialError
+	var ie *tools.IOError
+	var pathErr *os.PathError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &ue), errors.As(err, &le):
+		return ExitUsage
+	case errors.As(err, &ve):
+		return ExitViolation
+	case errors.As(err, &pe):
+		return ExitPartial
+	case errors.As(err, &ie), errors.As(err, &pathErr):
+		return ExitIO
+	}
+	return ExitFailure
+}
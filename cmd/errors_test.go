@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-exit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ioutil.WriteFile(filepath.Join(dir, "missing.go"), []byte("package a\n"), 0644)
+	nothere := filepath.Join(dir, "nothere.go")
+
+	tests := []struct {
+		command string
+		code    int
+	}{
+		{"check " + dir + " --bogus", ExitUsage},
+		{"check " + dir + " -l nosuch", ExitUsage},
+		{"check " + dir + " -d -f", ExitUsage},
+		{"check " + dir + " -l mit", ExitViolation},
+		{"check " + nothere + " -l mit", ExitIO},
+		{"check " + dir + " " + nothere + " -l mit", ExitPartial},
+		{"check " + dir + " -f -l mit", ExitUsage},
+		{"check " + filepath.Join(dir, "missing.go") + " -d -l mit", ExitUsage},
+		{"check " + filepath.Join(dir, "*.txt") + " -l mit", ExitUsage},
+		{"check " + filepath.Join(dir, "a**", "*.go") + " -l mit", ExitUsage},
+		{"undo a b", ExitUsage},
+	}
+
+	for _, test := range tests {
+		lcmd := newRootCmd()
+		lcmd.SetArgs(strings.Split(test.command, " "))
+		lcmd.SetOut(ioutil.Discard)
+		lcmd.SetErr(ioutil.Discard)
+		err := lcmd.Execute()
+		if code := ExitCode(err); code != test.code {
+			t.Errorf("liquid %s: exit code is %d, want %d (%v)", test.command, code, test.code, err)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+//gitStatus caches status of git work trees so that git is called once per work tree.
+type gitStatus struct {
+	tops  map[string]string
+	dirty map[string]map[string]bool
+}
+
+func newGitStatus() *gitStatus {
+	return &gitStatus{make(map[string]string), make(map[string]map[string]bool)}
+}
+
+//runGit runs git in dir and returns its stdout.
+func runGit(dir string, args ...string) ([]byte, error) {
+	return runGitInput(dir, nil, args...)
+}
+
+//runGitInput runs git in dir with in as its stdin and returns its stdout.
+func runGitInput(dir string, in []byte, args ...string) ([]byte, error) {
+	c := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if in != nil {
+		c.Stdin = bytes.NewReader(in)
+	}
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil && stderr.Len() > 0 {
+		return out, &gitError{args, strings.TrimSpace(stderr.String())}
+	}
+	return out, err
+}
+
+type gitError struct {
+	args []string
+	msg  string
+}
+
+func (e *gitError) Error() string {
+	return "git " + strings.Join(e.args, " ") + ": " + e.msg
+}
+
+//topLevel returns top level directory of git work tree that contains dir. If dir is not in git work tree, it returns "".
+func (g *gitStatus) topLevel(dir string) string {
+	top, ok := g.tops[dir]
+	if !ok {
+		out, err := runGit(dir, "rev-parse", "--show-toplevel")
+		if err == nil {
+			top = filepath.Clean(strings.TrimSpace(string(out)))
+		}
+		g.tops[dir] = top
+	}
+	return top
+}
+
+//isDirty reports whether fp has staged or unstaged changes that are not committed, or is not tracked by git. Ignored files and files out of git work tree are not dirty.
+func (g *gitStatus) isDirty(fp string) (bool, error) {
+	ap, err := filepath.Abs(fp)
+	if err != nil {
+		return false, err
+	}
+	dir, err := filepath.EvalSymlinks(filepath.Dir(ap))
+	if err != nil {
+		return false, err
+	}
+	top := g.topLevel(dir)
+	if top == "" {
+		return false, nil
+	}
+
+	d, ok := g.dirty[top]
+	if !ok {
+		out, err := runGit(top, "status", "--porcelain", "-z", "--untracked-files=all")
+		if err != nil {
+			return false, err
+		}
+		d = make(map[string]bool)
+		for _, p := range parseStatusPaths(out) {
+			d[filepath.Join(top, filepath.FromSlash(p))] = true
+		}
+		g.dirty[top] = d
+	}
+
+	return d[filepath.Join(dir, filepath.Base(ap))], nil
+}
+
+//parseStatusPaths parses output of git status --porcelain -z and returns paths in it.
+func parseStatusPaths(out []byte) []string {
+	var paths []string
+	fields := strings.Split(string(out), "\x00")
+	for i := 0; i < len(fields); i++ {
+		f := fields[i]
+		if len(f) < 4 {
+			continue
+		}
+		paths = append(paths, f[3:])
+		if f[0] == 'R' || f[0] == 'C' || f[1] == 'R' || f[1] == 'C' {
+			//renamed or copied entry in index or work tree is followed by its original path.
+			i++
+		}
+	}
+	return paths
+}
+
+//gitChangedFiles returns absolute paths of files changed since revision since, or staged in git index if staged is true. Deleted files are not included.
+//git is run in the work tree of each input path, so input paths can be in different repositories from current directory.
+func gitChangedFiles(input []string, since string, staged bool) ([]string, error) {
+	args := []string{"diff", "--name-only", "-z", "--diff-filter=ACMR"}
+	if staged {
+		args = append(args, "--cached")
+	}
+	if since != "" {
+		args = append(args, since)
+	}
+	args = append(args, "--")
+
+	g := newGitStatus()
+	done := make(map[string]bool)
+	var files []string
+	for _, in := range input {
+		top, err := gitTopLevelOf(g, in)
+		if err != nil {
+			return nil, err
+		}
+		if done[top] {
+			continue
+		}
+		done[top] = true
+
+		out, err := runGit(top, args...)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, splitGitPaths(top, out)...)
+	}
+
+	return files, nil
+}
+
+//gitTopLevelOf returns top level directory of git work tree that contains the file or directory p. It reports error if p is not in git work tree.
+func gitTopLevelOf(g *gitStatus, p string) (string, error) {
+	ap, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	if fi, err := os.Stat(ap); err != nil || !fi.IsDir() {
+		ap = filepath.Dir(ap)
+	}
+	if rp, err := filepath.EvalSymlinks(ap); err == nil {
+		ap = rp
+	}
+	top := g.topLevel(ap)
+	if top == "" {
+		return "", fmt.Errorf("%s is not in git work tree", p)
+	}
+	return top, nil
+}
+
+//gitTopLevel returns top level directory of git work tree of current directory.
+func gitTopLevel() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return gitTopLevelOf(newGitStatus(), wd)
+}
+
+//splitGitPaths splits NUL separated paths output by git and joins them to top.
+func splitGitPaths(top string, out []byte) []string {
+	var files []string
+	for _, p := range strings.Split(string(out), "\x00") {
+		if p != "" {
+			files = append(files, filepath.Join(top, filepath.FromSlash(p)))
+		}
+	}
+	return files
+}
+
+//gitUnstagedFiles returns absolute paths of files whose working tree content differs from git index.
+func gitUnstagedFiles(top string) ([]string, error) {
+	out, err := runGit(top, "diff", "--name-only", "-z", "--")
+	if err != nil {
+		return nil, err
+	}
+	return splitGitPaths(top, out), nil
+}
+
+//gitReadIndex returns mode and content of the file rel staged in git index.
+func gitReadIndex(top, rel string) (string, []byte, error) {
+	out, err := runGit(top, "ls-files", "-s", "-z", "--", rel)
+	if err != nil {
+		return "", nil, err
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("%s is not staged", rel)
+	}
+	blob, err := runGit(top, "cat-file", "blob", fields[1])
+	return fields[0], blob, err
+}
+
+//gitStage writes data as content of the file rel to git index with mode.
+func gitStage(top, rel, mode string, data []byte) error {
+	out, err := runGitInput(top, data, "hash-object", "-w", "--stdin", "--path", rel)
+	if err != nil {
+		return err
+	}
+	_, err = runGit(top, "update-index", "--cacheinfo", mode+","+strings.TrimSpace(string(out))+","+rel)
+	return err
+}
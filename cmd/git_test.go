@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//newTestRepo creates git repository in a temporary directory and returns its path. Test is skipped if git is not available.
+func newTestRepo(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "liquid-git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, _ = filepath.EvalSymlinks(dir)
+	if _, err := runGit(dir, "init", "-q"); err != nil {
+		os.RemoveAll(dir)
+		t.Skip("git is not available:", err)
+	}
+	runGit(dir, "config", "user.name", "liquid")
+	runGit(dir, "config", "user.email", "liquid@example.com")
+	return dir
+}
+
+//commitFiles writes files to repository dir and commits them.
+func commitFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+	if _, err := runGit(dir, "add", "-A"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(dir, "commit", "-q", "-m", "test"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsDirty(t *testing.T) {
+	dir := newTestRepo(t)
+	defer os.RemoveAll(dir)
+	commitFiles(t, dir, map[string]string{
+		"clean.go":    "package a\n",
+		"modified.go": "package a\n",
+		"staged.go":   "package a\n",
+		"old.go":      "package a\n",
+		".gitignore":  "ignored.go\n",
+	})
+
+	ioutil.WriteFile(filepath.Join(dir, "modified.go"), []byte("package b\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "staged.go"), []byte("package b\n"), 0644)
+	runGit(dir, "add", "staged.go")
+	runGit(dir, "mv", "old.go", "renamed.go")
+	ioutil.WriteFile(filepath.Join(dir, "untracked.go"), []byte("package a\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "ignored.go"), []byte("package a\n"), 0644)
+
+	expected := map[string]bool{
+		"clean.go":     false,
+		"modified.go":  true,
+		"staged.go":    true,
+		"renamed.go":   true,
+		"untracked.go": true,
+		"ignored.go":   false,
+	}
+	g := newGitStatus()
+	for name, e := range expected {
+		dirty, err := g.isDirty(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dirty != e {
+			t.Errorf("%s: expected dirty to be %t, but got %t", name, e, dirty)
+		}
+	}
+
+	out, err := ioutil.TempDir("", "liquid-nogit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(out)
+	if dirty, err := g.isDirty(filepath.Join(out, "a.go")); err != nil || dirty {
+		t.Errorf("file out of git work tree must not be dirty: %t, %v", dirty, err)
+	}
+}
+
+func TestParseStatusPaths(t *testing.T) {
+	out := "R  new.go\x00old.go\x00 R moved.go\x00orig.go\x00 M a.go\x00?? b.go\x00"
+	got := parseStatusPaths([]byte(out))
+	expected := []string{"new.go", "moved.go", "a.go", "b.go"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, but got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %v, but got %v", expected, got)
+		}
+	}
+}
+
+func TestGitChangedFiles(t *testing.T) {
+	dir := newTestRepo(t)
+	defer os.RemoveAll(dir)
+	commitFiles(t, dir, map[string]string{"a.go": "package a\n", "b.go": "package a\n"})
+	ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte("package b\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "c.go"), []byte("package a\n"), 0644)
+	runGit(dir, "add", "c.go")
+
+	//git must be run in the repository of input, not in current directory.
+	got, err := gitChangedFiles([]string{dir}, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != filepath.Join(dir, "c.go") {
+		t.Errorf("staged files: expected [c.go], but got %v", got)
+	}
+
+	got, err = gitChangedFiles([]string{filepath.Join(dir, "a.go"), dir}, "HEAD", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != filepath.Join(dir, "a.go") || got[1] != filepath.Join(dir, "c.go") {
+		t.Errorf("files changed since HEAD: expected [a.go c.go], but got %v", got)
+	}
+
+	out, err := ioutil.TempDir("", "liquid-nogit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(out)
+	if _, err := gitChangedFiles([]string{out}, "", true); err == nil {
+		t.Error("input out of git work tree must be reported")
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//hasGlobMeta reports whether p contains glob pattern characters.
+func hasGlobMeta(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+//expandGlob returns paths that match pattern in lexical order. In addition to syntax of filepath.Match, "**" in pattern matches any number of directories.
+//expandGlob does not depend on shell, so pattern is interpreted in the same way on every platform.
+func expandGlob(pattern string) ([]string, error) {
+	segs := strings.Split(filepath.ToSlash(pattern), "/")
+
+	hasDoubleStar := false
+	for _, s := range segs {
+		if s == "**" {
+			hasDoubleStar = true
+		} else if strings.Contains(s, "**") {
+			return nil, usageErrorf("%s: ** must be a whole path element", pattern)
+		}
+	}
+	if !hasDoubleStar {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, usageErrorf("%s: %s", pattern, err)
+		}
+		return matches, nil
+	}
+
+	//base is the longest leading part of pattern that does not contain glob pattern.
+	i := 0
+	for i < len(segs) && !hasGlobMeta(segs[i]) {
+		i++
+	}
+	base := filepath.FromSlash(strings.Join(segs[:i], "/"))
+	if base == "" {
+		if i > 0 {
+			base = string(filepath.Separator)
+		} else {
+			base = "."
+		}
+	}
+	rest := segs[i:]
+
+	var matches []string
+	err := fs.WalkDir(os.DirFS(base), ".", func(name string, d fs.DirEntry, err error) error {
+		//unreadable directories and base that does not exist match nothing.
+		if err != nil || name == "." {
+			return nil
+		}
+		ok, err := matchSegments(rest, strings.Split(name, "/"))
+		if err != nil {
+			return usageErrorf("%s: %s", pattern, err)
+		}
+		if ok {
+			matches = append(matches, filepath.Join(base, filepath.FromSlash(name)))
+		}
+		return nil
+	})
+	sort.Strings(matches)
+
+	return matches, err
+}
+
+//matchSegments matches path elements of name with elements of pattern.
+func matchSegments(pattern, name []string) (bool, error) {
+	if len(pattern) == 0 {
+		return len(name) == 0, nil
+	}
+	if pattern[0] == "**" {
+		ok, err := matchSegments(pattern[1:], name)
+		if ok || err != nil || len(name) == 0 {
+			return ok, err
+		}
+		return matchSegments(pattern, name[1:])
+	}
+	if len(name) == 0 {
+		return false, nil
+	}
+	ok, err := path.Match(pattern[0], name[0])
+	if !ok || err != nil {
+		return false, err
+	}
+	return matchSegments(pattern[1:], name[1:])
+}
+
+//isGoInput reports whether p is a .go file or a directory, which can be input of liquid.
+func isGoInput(p string) bool {
+	if filepath.Ext(p) == ".go" {
+		return true
+	}
+	fi, err := os.Stat(p)
+	return err == nil && fi.IsDir()
+}
+
+//filterGoInputs returns paths that are .go files or directories.
+func filterGoInputs(paths []string) []string {
+	var r []string
+	for _, p := range paths {
+		if isGoInput(p) {
+			r = append(r, p)
+		}
+	}
+	return r
+}
+
+//expandInputs expands glob patterns in input paths. Paths that are not pattern are kept as it is.
+func expandInputs(input []string) ([]string, []error) {
+	var errs []error
+	expanded := make([]string, 0, len(input))
+	for _, in := range input {
+		if !hasGlobMeta(in) {
+			expanded = append(expanded, in)
+			continue
+		}
+		matches, err := expandGlob(in)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		//patterns like dir/* also match files other than source code.
+		matches = filterGoInputs(matches)
+		if len(matches) == 0 {
+			errs = append(errs, usageErrorf("%s: no file matches", in))
+		}
+		expanded = append(expanded, matches...)
+	}
+	return expanded, errs
+}
+
+//checkInputKinds removes input paths that are not directories if dirOnly is true, or that are not files if fileOnly is true.
+func checkInputKinds(input []string, dirOnly, fileOnly bool) ([]string, []error) {
+	if !dirOnly && !fileOnly {
+		return input, nil
+	}
+
+	var errs []error
+	checked := make([]string, 0, len(input))
+	for _, in := range input {
+		fi, err := os.Stat(in)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case dirOnly && !fi.IsDir():
+			errs = append(errs, usageErrorf("%s is not directory", in))
+		case fileOnly && fi.IsDir():
+			errs = append(errs, usageErrorf("%s is directory", in))
+		default:
+			checked = append(checked, in)
+		}
+	}
+	return checked, errs
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExpandGlob(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-glob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{"a.go", "b.txt", "internal/c.go", "internal/x/d.go", "internal/x/y/e.go", "other/f.go"}
+	for _, f := range files {
+		fp := filepath.Join(dir, filepath.FromSlash(f))
+		os.MkdirAll(filepath.Dir(fp), 0755)
+		ioutil.WriteFile(fp, nil, 0644)
+	}
+
+	cases := []struct {
+		pattern string
+		expect  []string
+	}{
+		{"*.go", []string{"a.go"}},
+		{"internal/**/*.go", []string{"internal/c.go", "internal/x/d.go", "internal/x/y/e.go"}},
+		{"**/x/*.go", []string{"internal/x/d.go"}},
+		{"**/*.txt", []string{"b.txt"}},
+		{"nothing/**/*.go", nil},
+	}
+
+	for _, c := range cases {
+		matches, err := expandGlob(filepath.Join(dir, c.pattern))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		var got []string
+		for _, m := range matches {
+			rel, _ := filepath.Rel(dir, m)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Errorf("%s: expected %v, but got %v", c.pattern, c.expect, got)
+		}
+	}
+}
+
+func TestGoInputs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-glob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := []string{"a.go", "README.md", "sub/b.go", "vendor/x/x.go", "testdata/t.go", ".hidden/h.go"}
+	for _, f := range files {
+		fp := filepath.Join(dir, filepath.FromSlash(f))
+		os.MkdirAll(filepath.Dir(fp), 0755)
+		ioutil.WriteFile(fp, []byte("package a\n"), 0644)
+	}
+
+	//glob matches other than .go files and directories are not input.
+	got, errs := expandInputs([]string{filepath.Join(dir, "*"), filepath.Join(dir, "*.md")})
+	expected := []string{filepath.Join(dir, ".hidden"), filepath.Join(dir, "a.go"), filepath.Join(dir, "sub"), filepath.Join(dir, "testdata"), filepath.Join(dir, "vendor")}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if len(errs) != 1 {
+		t.Errorf("pattern that matches no .go file must be reported: %v", errs)
+	}
+
+	//explicit file that is not .go file is skipped, and vendor, testdata and hidden directories are not walked.
+	targets, errs := listHeadTargets([]string{filepath.Join(dir, "README.md"), dir}, true, &licenseCache{})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	var paths []string
+	for _, tg := range targets {
+		if tg.path == filepath.Join(dir, "README.md") {
+			if tg.skip == "" {
+				t.Error("README.md must be skipped")
+			}
+			continue
+		}
+		rel, _ := filepath.Rel(dir, tg.path)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	if expected := []string{"a.go", "sub/b.go"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, but got %v", expected, paths)
+	}
+
+	//directory specified explicitly is walked even if it is ignored in walking.
+	targets, _ = listHeadTargets([]string{filepath.Join(dir, "vendor")}, true, &licenseCache{})
+	if len(targets) != 1 {
+		t.Errorf("files in specified vendor directory must be listed: %v", targets)
+	}
+}
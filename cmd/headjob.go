@@ -0,0 +1,365 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+//headTarget is a file that sethead rewrites, with the license resolved for its directory.
+type headTarget struct {
+	path    string
+	fi      os.FileInfo
+	license *tools.License
+	//skip is reason why the target is not rewritten. If it is empty, the target is rewritten.
+	skip string
+	//fsys is file system that the target is read from, and name is slash separated name of the target in fsys.
+	fsys fs.FS
+	name string
+}
+
+//fileTarget returns target of the file fp, which is read from file system of its directory.
+func fileTarget(fp string, fi os.FileInfo, l *tools.License) headTarget {
+	return headTarget{path: fp, fi: fi, license: l, fsys: os.DirFS(filepath.Dir(fp)), name: filepath.Base(fp)}
+}
+
+//read reads content of t from its file system.
+func (t *headTarget) read() ([]byte, error) {
+	b, err := fs.ReadFile(t.fsys, t.name)
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		pe.Path = t.path
+	}
+	return b, err
+}
+
+//licenseCache resolves license of directories and caches it, so that LICENSE file of each directory is read only once.
+type licenseCache struct {
+	def      *tools.License
+	detect   bool
+	licenses map[string]*tools.License
+}
+
+func newLicenseCache(def *tools.License, LIsNotSet bool, config *Config) *licenseCache {
+	return &licenseCache{
+		def:      def,
+		detect:   LIsNotSet && config.License["fix"] == "",
+		licenses: make(map[string]*tools.License),
+	}
+}
+
+//get returns license for files in dir.
+func (lc *licenseCache) get(dir string) *tools.License {
+	if !lc.detect {
+		return lc.def
+	}
+	l, ok := lc.licenses[dir]
+	if !ok {
+		l = tools.GetDirLicense(dir)
+		if l == nil {
+			l = lc.def
+		}
+		lc.licenses[dir] = l
+	}
+	return l
+}
+
+//addTargetFlags adds flags that select target files to c. recursive is default value of recursively flag.
+func addTargetFlags(c *cobra.Command, recursive bool) {
+	c.Flags().BoolP("directory", "d", false, "If this flag is true, input paths must be directories.")
+	c.Flags().BoolP("file", "f", false, "If this flag is true, input paths must be files.")
+	c.Flags().BoolP("recursively", "r", recursive, "This flag decide whether process subdirectory recursively or not.")
+	c.Flags().String("since", "", "process only files changed since specified git revision. Input paths limit the files to under them.")
+	c.Flags().Bool("staged", false, "process only files staged in git index. Input paths limit the files to under them.")
+	c.Flags().String("files-from", "", "read input paths from the file. If it is \"-\", paths are read from stdin. Paths are separated by new line, and blank lines and lines beginning with # are ignored.")
+	c.Flags().BoolP("null", "0", false, "If this flag is true, paths read by files-from flag are separated by NUL character instead of new line.")
+	c.Flags().IntP("jobs", "j", runtime.NumCPU(), "number of files processed in parallel (default is number of CPUs)")
+}
+
+//resolveTargets lists target files from args and flags of c. Glob patterns in args are expanded. If no path is specified, current directory is the input.
+//Errors of each input are returned as errs and the other inputs are still listed. If targets cannot be listed at all, err is returned.
+func resolveTargets(c *cobra.Command, lc *licenseCache) ([]headTarget, []error, error) {
+	filesFrom, err := c.Flags().GetString("files-from")
+	if err != nil {
+		panic(err)
+	}
+	null, err := c.Flags().GetBool("null")
+	if err != nil {
+		panic(err)
+	}
+
+	dirOnly, err := c.Flags().GetBool("directory")
+	if err != nil {
+		panic(err)
+	}
+	fileOnly, err := c.Flags().GetBool("file")
+	if err != nil {
+		panic(err)
+	}
+	if dirOnly && fileOnly {
+		return nil, nil, usageErrorf("directory flag and file flag cannot be used together")
+	}
+
+	input, errs := expandInputs(c.Flags().Args())
+	if filesFrom != "" {
+		list, err := readFileList(filesFrom, c.InOrStdin(), null)
+		if err != nil {
+			return nil, nil, err
+		}
+		input = append(input, list...)
+	} else if len(c.Flags().Args()) < 1 {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, nil, fmt.Errorf("input is empty and current directory cannnot be gotten: %w", err)
+		}
+		input = []string{wd}
+	}
+
+	input, kerrs := checkInputKinds(input, dirOnly, fileOnly)
+	errs = append(errs, kerrs...)
+
+	r, err := c.Flags().GetBool("recursively")
+	if err != nil {
+		panic(err)
+	}
+	since, err := c.Flags().GetString("since")
+	if err != nil {
+		panic(err)
+	}
+	staged, err := c.Flags().GetBool("staged")
+	if err != nil {
+		panic(err)
+	}
+
+	var targets []headTarget
+	var lerrs []error
+	if since == "" && !staged {
+		targets, lerrs = listHeadTargets(input, r, lc)
+	} else {
+		changed, err := gitChangedFiles(input, since, staged)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets, lerrs = listHeadTargets(filterUnder(changed, input), false, lc)
+	}
+
+	return targets, append(errs, lerrs...), nil
+}
+
+//readFileList reads list of .go files and directories from the file p, or from stdin if p is "-". Paths are separated by NUL if null is true, and by new line otherwise.
+//In new line separated list, blank lines and lines beginning with "#" are ignored.
+func readFileList(p string, stdin io.Reader, null bool) ([]string, error) {
+	var b []byte
+	var err error
+	if p == "-" {
+		b, err = ioutil.ReadAll(stdin)
+	} else {
+		b, err = ioutil.ReadFile(p)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sep := "\n"
+	if null {
+		sep = "\x00"
+	}
+	var list []string
+	for _, l := range strings.Split(string(b), sep) {
+		if !null {
+			l = strings.TrimSuffix(l, "\r")
+			if strings.HasPrefix(l, "#") {
+				continue
+			}
+		}
+		//lists like output of git ls-files contain files other than source code.
+		if l == "" {
+			continue
+		}
+		if isGoInput(l) {
+			list = append(list, l)
+		}
+	}
+	return list, nil
+}
+
+//filterUnder returns .go files of paths that are input paths or are under input directories.
+func filterUnder(paths, input []string) []string {
+	var dirs []string
+	for _, in := range input {
+		ap, err := filepath.Abs(in)
+		if err != nil {
+			continue
+		}
+		if rp, err := filepath.EvalSymlinks(ap); err == nil {
+			ap = rp
+		}
+		dirs = append(dirs, ap)
+	}
+
+	var r []string
+	for _, p := range paths {
+		if filepath.Ext(p) != ".go" {
+			continue
+		}
+		for _, d := range dirs {
+			if p == d || strings.HasPrefix(p, d+string(filepath.Separator)) {
+				r = append(r, p)
+				break
+			}
+		}
+	}
+	return r
+}
+
+//listHeadTargets lists .go files in input paths. If recursive is true, .go files in subdirectories are also listed. Returned targets are sorted by path and have no duplication.
+func listHeadTargets(input []string, recursive bool, lc *licenseCache) ([]headTarget, []error) {
+	var errs []error
+	seen := make(map[string]bool)
+	targets := make([]headTarget, 0, len(input))
+
+	add := func(t headTarget) {
+		if seen[t.path] {
+			return
+		}
+		seen[t.path] = true
+		targets = append(targets, t)
+	}
+
+	for _, inputPath := range input {
+		p := filepath.Clean(inputPath)
+		ii, err := os.Stat(p)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !ii.IsDir() {
+			t := fileTarget(p, ii, lc.get(filepath.Dir(p)))
+			if filepath.Ext(p) != ".go" {
+				t.skip = "it is not a .go file"
+			}
+			add(t)
+			continue
+		}
+
+		fsys := os.DirFS(p)
+		names, werrs := tools.GoFiles(fsys, ".", recursive)
+		for _, err := range werrs {
+			errs = append(errs, rootError(p, err))
+		}
+		for _, name := range names {
+			fi, err := fs.Stat(fsys, name)
+			if err != nil {
+				errs = append(errs, rootError(p, err))
+				continue
+			}
+			fp := filepath.Join(p, filepath.FromSlash(name))
+			add(headTarget{path: fp, fi: fi, license: lc.get(filepath.Dir(fp)), fsys: fsys, name: name})
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].path < targets[j].path
+	})
+
+	return targets, errs
+}
+
+//rootError makes path of err returned by file system of the directory root into path in OS file system.
+func rootError(root string, err error) error {
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		pe.Path = filepath.Join(root, filepath.FromSlash(pe.Path))
+	}
+	return err
+}
+
+//skipDirtyTargets marks targets that have uncommitted changes in git work tree as skipped.
+func skipDirtyTargets(targets []headTarget) []error {
+	var errs []error
+	g := newGitStatus()
+	for i := range targets {
+		if targets[i].skip != "" {
+			continue
+		}
+		dirty, err := g.isDirty(targets[i].path)
+		if err != nil {
+			errs = append(errs, err)
+			targets[i].skip = "cannot get git status"
+		} else if dirty {
+			targets[i].skip = "it has uncommitted changes (use --force to modify it)"
+		}
+	}
+	return errs
+}
+
+//runHeadJobs sets license header of targets with n workers. Files are written according to opt and recorded to j. Reports are returned in the same order as targets.
+func runHeadJobs(targets []headTarget, n int, ho *tools.HeaderOptions, opt *tools.WriteOption, j *Journal) []*FileReport {
+	reports := make([]*FileReport, len(targets))
+	parallel(n, len(targets), func(i int) {
+		t := targets[i]
+		if t.skip != "" {
+			reports[i] = newFileReport(t.path, ActionSkipped, nil).skip(t.skip)
+			return
+		}
+		reports[i], _ = setTargetHeader(&t, ho, opt, j)
+	})
+	return reports
+}
+
+//parallel calls f with each index in [0, count) using n workers.
+func parallel(n, count int, f func(i int)) {
+	if n < 1 {
+		n = 1
+	}
+
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				f(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		idx <- i
+	}
+	close(idx)
+	wg.Wait()
+}
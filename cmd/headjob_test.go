@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadFileList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-files-from")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	a := filepath.Join(dir, "a.go")
+	b := filepath.Join(dir, "b.go")
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0755)
+
+	equal := func(got, expected []string) bool {
+		return strings.Join(got, "\n") == strings.Join(expected, "\n")
+	}
+
+	in := "# generated list\r\n" + a + "\r\n\r\n" + filepath.Join(dir, "README.md") + "\n" + sub + "\n\n#" + b + "\n" + b + "\n"
+	got, err := readFileList("-", strings.NewReader(in), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{a, sub, b}; !equal(got, expected) {
+		t.Errorf("list from stdin: expected %v, but got %v", expected, got)
+	}
+
+	list := filepath.Join(dir, "list")
+	ioutil.WriteFile(list, []byte(a+"\x00\x00#"+b+"\x00"+sub+"\x00"), 0644)
+	got, err = readFileList(list, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//NUL separated paths are used as they are.
+	if expected := []string{a, "#" + b, sub}; !equal(got, expected) {
+		t.Errorf("NUL separated list: expected %v, but got %v", expected, got)
+	}
+
+	if _, err := readFileList(filepath.Join(dir, "none"), nil, false); err == nil {
+		t.Error("list file that does not exist must be reported")
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+const (
+	hookName        = "pre-commit"
+	hookMarker      = "# liquid pre-commit hook"
+	chainedHookName = hookName + ".liquid-chained"
+)
+
+// newHookCmd represents the hook command
+func newHookCmd() *cobra.Command {
+	hookCmd := &cobra.Command{
+		Use:   "hook",
+		Short: "manage git pre-commit hook that runs liquid.",
+		Long:  `liquid hook installs or uninstalls git pre-commit hook of the repository in current directory. The hook runs liquid on staged files.`,
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "install git pre-commit hook that runs liquid.",
+		Long: `liquid hook install installs git pre-commit hook that runs liquid check on staged files. If fix flag is on, the hook fixes license header of staged files and stages them instead.
+If the repository already has pre-commit hook, the hook is kept and called before liquid.`,
+		Args: usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fix, err := cmd.Flags().GetBool("fix")
+			if err != nil {
+				panic(err)
+			}
+			extra, err := cmd.Flags().GetString("args")
+			if err != nil {
+				panic(err)
+			}
+
+			rp, err := newReporter(cmd, "hook install")
+			if err != nil {
+				return err
+			}
+			p, err := InstallHook(fix, extra)
+			if err != nil {
+				rp.report(newFileReport(p, ActionFailed, nil).fail(err))
+			} else {
+				rp.report(newFileReport(p, ActionInstalled, nil))
+			}
+			err = rp.finish()
+			if err != nil {
+				return err
+			}
+			return rp.err()
+		},
+	}
+	installCmd.Flags().Bool("fix", false, "If this flag is true, the hook fixes license header of staged files instead of checking.")
+	installCmd.Flags().String("args", "", "additional arguments of liquid run by the hook (e.g. \"-l apache -a author\")")
+
+	uninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "uninstall git pre-commit hook installed by liquid.",
+		Args:  usageArgs(cobra.NoArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rp, err := newReporter(cmd, "hook uninstall")
+			if err != nil {
+				return err
+			}
+			p, err := UninstallHook()
+			if err != nil {
+				rp.report(newFileReport(p, ActionFailed, nil).fail(err))
+			} else {
+				rp.report(newFileReport(p, ActionUninstalled, nil))
+			}
+			err = rp.finish()
+			if err != nil {
+				return err
+			}
+			return rp.err()
+		},
+	}
+
+	hookCmd.AddCommand(installCmd)
+	hookCmd.AddCommand(uninstallCmd)
+
+	return hookCmd
+}
+
+//hooksDir returns hooks directory of git repository of current directory.
+func hooksDir() (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	out, err := runGit(wd, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	d := filepath.FromSlash(strings.TrimSpace(string(out)))
+	if !filepath.IsAbs(d) {
+		d = filepath.Join(wd, d)
+	}
+	return d, nil
+}
+
+//hookScript returns pre-commit hook script that runs liquid with extra arguments.
+func hookScript(fix bool, extra string) string {
+	var sb strings.Builder
+	sb.WriteString("#!/bin/sh\n")
+	sb.WriteString(hookMarker + "\n")
+	sb.WriteString("# installed by liquid hook install. Remove it with liquid hook uninstall.\n\n")
+	sb.WriteString("chained=\"$(dirname \"$0\")/" + chainedHookName + "\"\n")
+	sb.WriteString("if [ -x \"$chained\" ]; then\n\t\"$chained\" \"$@\" || exit $?\nfi\n\n")
+	if extra != "" {
+		extra = " " + extra
+	}
+	if fix {
+		sb.WriteString("exec liquid sethead --staged --fix" + extra + "\n")
+	} else {
+		sb.WriteString("exec liquid check --staged" + extra + "\n")
+	}
+	return sb.String()
+}
+
+func isLiquidHook(p string) (bool, error) {
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Contains(b, []byte(hookMarker)), nil
+}
+
+//InstallHook installs pre-commit hook that runs liquid to git repository of current directory, and returns path of the hook.
+//Existing pre-commit hook that is not installed by liquid is renamed and called from the new hook.
+func InstallHook(fix bool, extra string) (string, error) {
+	dir, err := hooksDir()
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	p := filepath.Join(dir, hookName)
+	cp := filepath.Join(dir, chainedHookName)
+	if e, _ := tools.IsExistFile(p); e {
+		ours, err := isLiquidHook(p)
+		if err != nil {
+			return "", err
+		}
+		if !ours {
+			if e, _ := tools.IsExistFile(cp); e {
+				return "", fmt.Errorf("cannot keep existing hook %s because %s already exists", p, cp)
+			}
+			err = os.Rename(p, cp)
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return p, ioutil.WriteFile(p, []byte(hookScript(fix, extra)), 0755)
+}
+
+//UninstallHook removes pre-commit hook installed by liquid from git repository of current directory, and restores the hook that was kept by InstallHook.
+func UninstallHook() (string, error) {
+	dir, err := hooksDir()
+	if err != nil {
+		return "", err
+	}
+
+	p := filepath.Join(dir, hookName)
+	ours, err := isLiquidHook(p)
+	if err != nil {
+		return "", err
+	}
+	if !ours {
+		return "", fmt.Errorf("%s is not installed by liquid", p)
+	}
+	err = os.Remove(p)
+	if err != nil {
+		return "", err
+	}
+
+	cp := filepath.Join(dir, chainedHookName)
+	if e, _ := tools.IsExistFile(cp); e {
+		err = os.Rename(cp, p)
+	}
+	return p, err
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHook(t *testing.T) {
+	dir := newTestRepo(t)
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(dir)
+
+	hooks := filepath.Join(dir, ".git", "hooks")
+	os.MkdirAll(hooks, 0755)
+	orig := "#!/bin/sh\necho existing >> \"$LIQUID_TEST_LOG\"\n"
+	ioutil.WriteFile(filepath.Join(hooks, hookName), []byte(orig), 0755)
+
+	p, err := InstallHook(false, "-l mit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != filepath.Join(hooks, hookName) {
+		t.Errorf("hook is installed to wrong path: %s", p)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(hooks, chainedHookName)); string(b) != orig {
+		t.Errorf("existing hook is not kept: %q", b)
+	}
+	b, _ := ioutil.ReadFile(p)
+	if !strings.HasSuffix(string(b), "\nexec liquid check --staged -l mit\n") {
+		t.Errorf("hook of check mode must run liquid check: %q", b)
+	}
+	if fi, err := os.Stat(p); err != nil || fi.Mode()&0100 == 0 {
+		t.Errorf("hook must be executable: %v", err)
+	}
+
+	//the hook runs existing hook first and then liquid.
+	bin := filepath.Join(dir, "bin")
+	os.Mkdir(bin, 0755)
+	ioutil.WriteFile(filepath.Join(bin, "liquid"), []byte("#!/bin/sh\necho liquid \"$@\" >> \"$LIQUID_TEST_LOG\"\n"), 0755)
+	log := filepath.Join(dir, "log")
+	c := exec.Command(p)
+	c.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"), "LIQUID_TEST_LOG="+log)
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("hook failed: %v: %s", err, out)
+	}
+	if b, _ := ioutil.ReadFile(log); string(b) != "existing\nliquid check --staged -l mit\n" {
+		t.Errorf("hook must run existing hook and liquid in order: %q", b)
+	}
+
+	//reinstall replaces the hook of liquid and keeps existing hook.
+	if _, err := InstallHook(true, ""); err != nil {
+		t.Fatal(err)
+	}
+	b, _ = ioutil.ReadFile(p)
+	if !strings.HasSuffix(string(b), "\nexec liquid sethead --staged --fix\n") {
+		t.Errorf("hook of fix mode must run liquid sethead: %q", b)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(hooks, chainedHookName)); string(b) != orig {
+		t.Errorf("existing hook is not kept on reinstall: %q", b)
+	}
+
+	if _, err := UninstallHook(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(p); string(b) != orig {
+		t.Errorf("existing hook is not restored: %q", b)
+	}
+	if _, err := os.Stat(filepath.Join(hooks, chainedHookName)); !os.IsNotExist(err) {
+		t.Error("kept hook must be moved back")
+	}
+	if _, err := UninstallHook(); err == nil {
+		t.Error("hook not installed by liquid must not be removed")
+	}
+}
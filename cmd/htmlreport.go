@@ -0,0 +1,320 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+// newReportCmd represents the report command
+func newReportCmd() *cobra.Command {
+	reportCmd := &cobra.Command{
+		Use:   "report [Paths of files or directories]",
+		Short: "generate HTML report of license headers of .go files in input directory or specified files.",
+		Long: `liquid report checks license header of .go files like liquid check, and writes the result as a self-contained static HTML page to index.html in the directory specified by html flag.
+The page shows header coverage per directory, distribution of licenses, files with missing or foreign headers and detected LICENSE files.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, err := cmd.Flags().GetString("html")
+			if err != nil {
+				panic(err)
+			}
+			if out == "" {
+				return usageErrorf("output directory must be specified by html flag")
+			}
+
+			config, license, author, LIsNotSet, err := ProcessArg(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				panic(err)
+			}
+
+			lc := newLicenseCache(license, LIsNotSet, config)
+			targets, errs, err := resolveTargets(cmd, lc)
+			if err != nil {
+				return err
+			}
+			for _, err := range errs {
+				cmd.Println(err)
+			}
+
+			now, err := tools.Now(nil)
+			if err != nil {
+				return &UsageError{err}
+			}
+			ho, err := getHeaderOptions(cmd, author, config)
+			if err != nil {
+				return err
+			}
+			reports := runCheckJobs(targets, jobs, ho)
+			data := newHTMLReport(reports, now)
+
+			err = os.MkdirAll(out, 0755)
+			if err != nil {
+				return err
+			}
+			fp := filepath.Join(out, "index.html")
+			f, err := os.Create(fp)
+			if err != nil {
+				return err
+			}
+			err = writeHTMLReport(f, data)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+
+			cmd.Printf("report of %d files is written to %s\r\n", data.Files, fp)
+			return failureError(reports, errs)
+		},
+	}
+
+	addTargetFlags(reportCmd, false)
+	reportCmd.Flags().String("html", "", "directory that HTML report is written to")
+
+	return reportCmd
+}
+
+//htmlReport is data of HTML report.
+type htmlReport struct {
+	Generated string
+	Files     int
+	//WithHeader is the number of files that have license header.
+	WithHeader int
+	Correct    int
+	Dirs       []*dirCoverage
+	Licenses   []*licenseCount
+	//Problems are files whose license header is missing or foreign, or that cannot be read.
+	Problems     []*FileReport
+	LicenseFiles []licenseFile
+}
+
+//dirCoverage is header coverage of a directory.
+type dirCoverage struct {
+	Dir        string
+	Files      int
+	WithHeader int
+	Correct    int
+}
+
+//Percent returns percentage of files that have license header.
+func (d *dirCoverage) Percent() int {
+	return percent(d.WithHeader, d.Files)
+}
+
+//CorrectPercent returns percentage of files whose license header is correct.
+func (d *dirCoverage) CorrectPercent() int {
+	return percent(d.Correct, d.Files)
+}
+
+//licenseCount is the number of files whose license header is the license.
+type licenseCount struct {
+	License string
+	Files   int
+	Percent int
+}
+
+//licenseFile is LICENSE file found in directory of reported files.
+type licenseFile struct {
+	Path    string
+	License string
+}
+
+func percent(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return n * 100 / total
+}
+
+//newHTMLReport aggregates reports of check into htmlReport.
+func newHTMLReport(reports []*FileReport, now time.Time) *htmlReport {
+	data := &htmlReport{Generated: now.Format(time.RFC1123)}
+	dirs := make(map[string]*dirCoverage)
+	licenses := make(map[string]*licenseCount)
+
+	for _, r := range reports {
+		if r.Action == ActionSkipped {
+			continue
+		}
+		data.Files++
+		dir := filepath.Dir(r.Path)
+		d, ok := dirs[dir]
+		if !ok {
+			d = &dirCoverage{Dir: relPath(dir)}
+			dirs[dir] = d
+			data.Dirs = append(data.Dirs, d)
+			if p := tools.FindLicenseFile(dir); p != "" {
+				data.LicenseFiles = append(data.LicenseFiles, licenseFile{relPath(p), licenseName(tools.GetDirLicense(dir))})
+			}
+		}
+		d.Files++
+
+		if r.Action == ActionFailed {
+			data.Problems = append(data.Problems, r)
+			continue
+		}
+
+		name := "none"
+		if r.status != tools.HeaderMissing {
+			d.WithHeader++
+			data.WithHeader++
+			name = r.OldLicense
+		}
+		if r.status == tools.HeaderCorrect {
+			d.Correct++
+			data.Correct++
+		}
+		if r.status == tools.HeaderMissing || r.status == tools.HeaderForeign {
+			data.Problems = append(data.Problems, r)
+		}
+
+		lc, ok := licenses[name]
+		if !ok {
+			lc = &licenseCount{License: name}
+			licenses[name] = lc
+			data.Licenses = append(data.Licenses, lc)
+		}
+		lc.Files++
+	}
+
+	sort.Slice(data.Dirs, func(i, j int) bool {
+		return data.Dirs[i].Dir < data.Dirs[j].Dir
+	})
+	for _, lc := range data.Licenses {
+		lc.Percent = percent(lc.Files, data.Files)
+	}
+	sort.Slice(data.Licenses, func(i, j int) bool {
+		if data.Licenses[i].Files != data.Licenses[j].Files {
+			return data.Licenses[i].Files > data.Licenses[j].Files
+		}
+		return data.Licenses[i].License < data.Licenses[j].License
+	})
+	sort.Slice(data.LicenseFiles, func(i, j int) bool {
+		return data.LicenseFiles[i].Path < data.LicenseFiles[j].Path
+	})
+
+	return data
+}
+
+//Percent returns percentage of files that have license header.
+func (data *htmlReport) Percent() int {
+	return percent(data.WithHeader, data.Files)
+}
+
+//CorrectPercent returns percentage of files whose license header is correct.
+func (data *htmlReport) CorrectPercent() int {
+	return percent(data.Correct, data.Files)
+}
+
+//writeHTMLReport writes data to w as HTML page. The page does not refer to any external resources.
+func writeHTMLReport(w io.Writer, data *htmlReport) error {
+	t, err := template.New("report").Funcs(template.FuncMap{"rel": relPath}).Parse(htmlReportTemplate)
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, data)
+}
+
+const htmlReportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>liquid license header report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.8em; text-align: left; }
+th { background: #f0f0f0; }
+td.num { text-align: right; }
+.bar { background: #eee; width: 12em; height: 0.9em; }
+.bar div { background: #4a4; height: 100%; }
+.missing { color: #b00; }
+.foreign { color: #a60; }
+.failed { color: #b00; }
+</style>
+</head>
+<body>
+<h1>liquid license header report</h1>
+<p>Generated at {{.Generated}}.</p>
+<p>{{.Files}} files: {{.WithHeader}} ({{.Percent}}%) have license header, {{.Correct}} ({{.CorrectPercent}}%) are correct.</p>
+
+<h2>Coverage per directory</h2>
+<table>
+<tr><th>Directory</th><th>Files</th><th>With header</th><th>Correct</th><th>Coverage</th></tr>
+{{- range .Dirs}}
+<tr><td>{{.Dir}}</td><td class="num">{{.Files}}</td><td class="num">{{.WithHeader}}</td><td class="num">{{.Correct}}</td><td><div class="bar"><div style="width: {{.Percent}}%"></div></div> {{.Percent}}%</td></tr>
+{{- end}}
+</table>
+
+<h2>License distribution</h2>
+<table>
+<tr><th>License</th><th>Files</th><th>Share</th></tr>
+{{- range .Licenses}}
+<tr><td>{{.License}}</td><td class="num">{{.Files}}</td><td class="num">{{.Percent}}%</td></tr>
+{{- end}}
+</table>
+
+<h2>Files with missing or foreign header</h2>
+{{- if .Problems}}
+<table>
+<tr><th>File</th><th>Status</th><th>Detail</th></tr>
+{{- range .Problems}}
+{{- if .Error}}
+<tr><td>{{rel .Path}}</td><td class="failed">failed</td><td>{{.Error}}</td></tr>
+{{- else}}
+<tr><td>{{rel .Path}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{range $i, $h := .OldHolders}}{{if $i}}, {{end}}{{$h}}{{end}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+{{- else}}
+<p>No file has missing or foreign header.</p>
+{{- end}}
+
+<h2>Detected LICENSE files</h2>
+{{- if .LicenseFiles}}
+<table>
+<tr><th>File</th><th>License</th></tr>
+{{- range .LicenseFiles}}
+<tr><td>{{.Path}}</td><td>{{.License}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No LICENSE file is found in directories of the files.</p>
+{{- end}}
+</body>
+</html>
+`
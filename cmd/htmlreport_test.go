@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+func TestReportHTML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+	os.Mkdir(src, 0755)
+	ioutil.WriteFile(filepath.Join(src, "missing.go"), []byte("package a\n"), 0644)
+	ioutil.WriteFile(filepath.Join(src, "foreign.go"), []byte("// Copyright (c) 2019 someone\n// other license\n\npackage a\n"), 0644)
+	ioutil.WriteFile(filepath.Join(src, "LICENSE"), []byte(tools.GetOSSLicense("mit").Text), 0644)
+
+	out := filepath.Join(dir, "out")
+	lcmd := newRootCmd()
+	lcmd.SetArgs([]string{"report", src, "-l", "mit", "-a", "author", "--html", out})
+	lcmd.SetOut(ioutil.Discard)
+	lcmd.SetErr(ioutil.Discard)
+	if err := lcmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(out, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(b)
+	for _, s := range []string{"2 files: 1 (50%) have license header", "foreign.go", "missing.go", "LICENSE</td><td>MIT License"} {
+		if !strings.Contains(page, s) {
+			t.Errorf("report does not contain %q", s)
+		}
+	}
+	if strings.Contains(page, "http") {
+		t.Error("report must not refer to external resources")
+	}
+}
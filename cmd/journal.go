@@ -0,0 +1,320 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+//journalRoot is the directory where journals are recorded. Relative path is resolved by journalDir.
+var journalRoot = filepath.Join(".liquid", "journal")
+
+//journalIDPattern matches run ids generated by NewJournal.
+var journalIDPattern = regexp.MustCompile(`^[0-9]{8}T[0-9]{6}\.[0-9]{6}Z(-[0-9]+)?$`)
+
+//journalDir returns the directory where journals are recorded. If journalRoot is relative, it is resolved from top level directory of git work tree of current directory, or from current directory if it is out of git work tree.
+//So liquid undo can be run from any subdirectory of the repository.
+func journalDir() string {
+	if filepath.IsAbs(journalRoot) {
+		return journalRoot
+	}
+	if top, err := gitTopLevel(); err == nil && top != "" {
+		return filepath.Join(top, journalRoot)
+	}
+	return journalRoot
+}
+
+//journalPath returns the directory of the journal of run id. It reports error if id is not a run id generated by NewJournal, so that id cannot point out of the journal directory.
+func journalPath(id string) (string, error) {
+	if !journalIDPattern.MatchString(id) {
+		return "", usageErrorf("invalid run id: %s", id)
+	}
+	return filepath.Join(journalDir(), id), nil
+}
+
+const journalEntriesFile = "entries.json"
+
+//journalClock returns time that run ids are made from.
+var journalClock tools.Clock = time.Now
+
+//Journal records original contents of files that a run of liquid modifies, so that the run can be undone by liquid undo.
+type Journal struct {
+	mu      sync.Mutex
+	dir     string
+	entries int
+	//backups are backup names of files already recorded. Original content of a file is recorded only at its first write.
+	backups map[string]string
+}
+
+//JournalEntry is a record of a file modified in a run.
+type JournalEntry struct {
+	//Path is absolute path of the file.
+	Path string `json:"path"`
+	//Backup is name of the file in journal directory that has original content. It is empty if the file is created by the run.
+	Backup string `json:"backup,omitempty"`
+	//Sum is sha256 checksum of the content written by the run.
+	Sum string `json:"sum"`
+}
+
+//NewJournal creates new journal for a run of command.
+func NewJournal(command string) (*Journal, error) {
+	root := journalDir()
+	err := os.MkdirAll(root, 0755)
+	if err != nil {
+		return nil, err
+	}
+	//runs started at the same time, like hooks run in parallel, get distinct directories by suffix.
+	id := journalClock().UTC().Format("20060102T150405.000000Z")
+	dir := filepath.Join(root, id)
+	for i := 1; ; i++ {
+		err = os.Mkdir(dir, 0755)
+		if !os.IsExist(err) {
+			break
+		}
+		dir = filepath.Join(root, id+"-"+strconv.Itoa(i))
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "command"), []byte(command+"\n"), 0644)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	return &Journal{dir: dir, backups: make(map[string]string)}, nil
+}
+
+//WriteFile records original content of fp and writes data to fp atomically. If j is nil, fp is written without record.
+//If fp already has data as its content, fp is not written.
+func (j *Journal) WriteFile(fp string, data []byte, opt *tools.WriteOption) error {
+	orig, err := ioutil.ReadFile(fp)
+	exist := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if exist && bytes.Equal(orig, data) {
+		return nil
+	}
+
+	if j == nil {
+		return tools.WriteFileAtomic(fp, data, opt)
+	}
+
+	ap, err := filepath.Abs(fp)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	e := JournalEntry{Path: ap, Sum: checksum(data)}
+	if b, ok := j.backups[ap]; ok {
+		e.Backup = b
+	} else if exist {
+		e.Backup = strconv.Itoa(j.entries) + ".orig"
+		err = ioutil.WriteFile(filepath.Join(j.dir, e.Backup), orig, 0600)
+		if err != nil {
+			return err
+		}
+	}
+	j.backups[ap] = e.Backup
+
+	err = j.appendEntry(e)
+	if err != nil {
+		return err
+	}
+	j.entries++
+
+	return tools.WriteFileAtomic(fp, data, opt)
+}
+
+func (j *Journal) appendEntry(e JournalEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(j.dir, journalEntriesFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(b, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+//Close finishes the journal. Journal that has no entry is removed.
+func (j *Journal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.entries == 0 {
+		return os.RemoveAll(j.dir)
+	}
+	return nil
+}
+
+//ID returns run id of j.
+func (j *Journal) ID() string {
+	return filepath.Base(j.dir)
+}
+
+func checksum(b []byte) string {
+	s := sha256.Sum256(b)
+	return hex.EncodeToString(s[:])
+}
+
+//ListJournals returns run ids of recorded journals in chronological order.
+func ListJournals() ([]string, error) {
+	fis, err := ioutil.ReadDir(journalDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(fis))
+	for _, fi := range fis {
+		if fi.IsDir() && journalIDPattern.MatchString(fi.Name()) {
+			ids = append(ids, fi.Name())
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return journalIDLess(ids[i], ids[j])
+	})
+	return ids, nil
+}
+
+//journalIDLess reports whether run a was started before run b. Runs started at the same time are ordered by their suffix.
+func journalIDLess(a, b string) bool {
+	ta, na := splitJournalID(a)
+	tb, nb := splitJournalID(b)
+	if ta != tb {
+		return ta < tb
+	}
+	return na < nb
+}
+
+func splitJournalID(id string) (string, int) {
+	i := strings.IndexByte(id, '-')
+	if i < 0 {
+		return id, 0
+	}
+	n, _ := strconv.Atoi(id[i+1:])
+	return id[:i], n
+}
+
+//ReadJournalEntries reads entries of the journal of run id. If a file is written more than once in the run, its last entry is returned in the place of its first entry.
+func ReadJournalEntries(id string) ([]JournalEntry, error) {
+	dir, err := journalPath(id)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filepath.Join(dir, journalEntriesFile))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	index := make(map[string]int)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e JournalEntry
+		err := json.Unmarshal(sc.Bytes(), &e)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[e.Path]; ok {
+			entries[i] = e
+			continue
+		}
+		index[e.Path] = len(entries)
+		entries = append(entries, e)
+	}
+
+	return entries, sc.Err()
+}
+
+//UndoJournal restores files recorded in the journal of run id and removes the journal.
+//If some of the files were changed after the run, UndoJournal restores nothing and returns error.
+func UndoJournal(id string) ([]JournalEntry, error) {
+	entries, err := ReadJournalEntries(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, e := range entries {
+		b, err := ioutil.ReadFile(e.Path)
+		if err != nil || checksum(b) != e.Sum {
+			changed = append(changed, e.Path)
+		}
+	}
+	if len(changed) > 0 {
+		return nil, fmt.Errorf("cannot undo run %s. files were changed after the run:\r\n%s", id, strings.Join(changed, "\r\n"))
+	}
+
+	dir, err := journalPath(id)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if e.Backup == "" {
+			err = os.Remove(e.Path)
+		} else {
+			var orig []byte
+			orig, err = ioutil.ReadFile(filepath.Join(dir, e.Backup))
+			if err == nil {
+				err = tools.WriteFileAtomic(e.Path, orig, nil)
+			}
+		}
+		if err != nil {
+			return entries[i+1:], err
+		}
+	}
+
+	return entries, os.RemoveAll(dir)
+}
@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "liquid-journal")
+	if err != nil {
+		panic(err)
+	}
+	journalRoot = dir
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func TestUndoJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-undo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	modified := filepath.Join(dir, "modified.go")
+	created := filepath.Join(dir, "created.go")
+	ioutil.WriteFile(modified, []byte("package a\n"), 0644)
+
+	run := func() string {
+		j, err := NewJournal("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := j.WriteFile(modified, []byte("// header\n\npackage a\n"), nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := j.WriteFile(created, []byte("package a\n"), nil); err != nil {
+			t.Fatal(err)
+		}
+		j.Close()
+		return j.ID()
+	}
+
+	id := run()
+	if _, err := UndoJournal(id); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(modified); string(b) != "package a\n" {
+		t.Errorf("modified file is not restored: %q", b)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("created file is not removed")
+	}
+
+	id = run()
+	ioutil.WriteFile(modified, []byte("// edited\n"), 0644)
+	if _, err := UndoJournal(id); err == nil || !strings.Contains(err.Error(), modified) {
+		t.Errorf("undo of changed files must be refused with the changed file: %v", err)
+	}
+	if _, err := os.Stat(created); err != nil {
+		t.Error("files must not be restored when undo is refused")
+	}
+}
+
+func TestJournalRewrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-undo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fp := filepath.Join(dir, "a.go")
+	ioutil.WriteFile(fp, []byte("package a\n"), 0644)
+	j, err := NewJournal("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	j.WriteFile(fp, []byte("// first\n\npackage a\n"), nil)
+	j.WriteFile(fp, []byte("// second\n\npackage a\n"), nil)
+	j.Close()
+
+	entries, err := UndoJournal(j.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("file written twice must be restored once: %+v", entries)
+	}
+	if b, _ := ioutil.ReadFile(fp); string(b) != "package a\n" {
+		t.Errorf("original content is not restored: %q", b)
+	}
+
+	for _, id := range []string{"../../x", "20190101T000000.000000Z/..", ""} {
+		if _, err := UndoJournal(id); ExitCode(err) != ExitUsage {
+			t.Errorf("invalid run id %q must be refused as usage error: %v", id, err)
+		}
+	}
+}
+
+func TestJournalDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-journal-dir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dir, _ = filepath.EvalSymlinks(dir)
+	if _, err := runGit(dir, "init", "-q"); err != nil {
+		t.Skip("git is not available:", err)
+	}
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0755)
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(sub)
+	root := journalRoot
+	defer func() { journalRoot = root }()
+	journalRoot = filepath.Join(".liquid", "journal")
+
+	if got, expected := journalDir(), filepath.Join(dir, ".liquid", "journal"); got != expected {
+		t.Errorf("journal directory must be in top level of repository: expected %s, but got %s", expected, got)
+	}
+}
+
+func TestNewJournalSameTime(t *testing.T) {
+	clock := journalClock
+	defer func() { journalClock = clock }()
+	start := time.Now()
+	journalClock = func() time.Time { return start }
+
+	const n = 12
+	ids := make(map[string]bool)
+	for i := 0; i < n; i++ {
+		j, err := NewJournal("test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids[j.ID()] {
+			t.Errorf("run id %s is shared by runs", j.ID())
+		}
+		ids[j.ID()] = true
+		defer j.Close()
+	}
+
+	list, err := ListJournals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for id := range ids {
+		found := false
+		for _, l := range list {
+			found = found || l == id
+		}
+		if !found {
+			t.Errorf("journal %s is not listed: %v", id, list)
+		}
+	}
+	if !journalIDLess("20190101T000000.000000Z-9", "20190101T000000.000000Z-10") || !journalIDLess("20190101T000000.000000Z", "20190101T000000.000000Z-1") {
+		t.Error("runs started at the same time must be ordered by suffix")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+//Types of JUnit XML report.
+type (
+	junitTestSuites struct {
+		XMLName  xml.Name         `xml:"testsuites"`
+		Name     string           `xml:"name,attr"`
+		Tests    int              `xml:"tests,attr"`
+		Failures int              `xml:"failures,attr"`
+		Errors   int              `xml:"errors,attr"`
+		Suites   []junitTestSuite `xml:"testsuite"`
+	}
+	junitTestSuite struct {
+		Name     string          `xml:"name,attr"`
+		Tests    int             `xml:"tests,attr"`
+		Failures int             `xml:"failures,attr"`
+		Errors   int             `xml:"errors,attr"`
+		Cases    []junitTestCase `xml:"testcase"`
+	}
+	junitTestCase struct {
+		Name      string        `xml:"name,attr"`
+		ClassName string        `xml:"classname,attr"`
+		Failure   *junitFailure `xml:"failure,omitempty"`
+		Error     *junitFailure `xml:"error,omitempty"`
+	}
+	junitFailure struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+		Text    string `xml:",chardata"`
+	}
+)
+
+//writeJUnit writes reports of check to w as JUnit XML. Test cases are grouped into test suites by directory if group is "dir", or by go module if group is "module".
+func writeJUnit(w io.Writer, reports []*FileReport, group string) error {
+	suites := make(map[string]*junitTestSuite)
+	var names []string
+
+	all := junitTestSuites{Name: "liquid check"}
+	for _, r := range reports {
+		if r.Action == ActionSkipped {
+			continue
+		}
+		dir := filepath.Dir(r.Path)
+		name := relPath(dir)
+		if group == "module" {
+			name = goModule(dir)
+		}
+		s, ok := suites[name]
+		if !ok {
+			s = &junitTestSuite{Name: name}
+			suites[name] = s
+			names = append(names, name)
+		}
+
+		tc := junitTestCase{Name: relPath(r.Path), ClassName: name}
+		switch {
+		case r.Err != nil:
+			tc.Error = &junitFailure{r.Error, "error", ""}
+			s.Errors++
+		case r.status != tools.HeaderCorrect:
+			tc.Failure = &junitFailure{r.status.Description(), r.Status, "expected header:\n" + r.expected}
+			s.Failures++
+		}
+		s.Cases = append(s.Cases, tc)
+		s.Tests++
+	}
+
+	sort.Strings(names)
+	for _, n := range names {
+		s := suites[n]
+		all.Tests += s.Tests
+		all.Failures += s.Failures
+		all.Errors += s.Errors
+		all.Suites = append(all.Suites, *s)
+	}
+
+	b, err := xml.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, xml.Header+string(b)+"\n")
+	return err
+}
+
+//goModule returns path of go module that contains dir. If dir is not in any module, dir itself is returned.
+func goModule(dir string) string {
+	if m := tools.ModulePath(dir); m != "" {
+		return m
+	}
+	return relPath(dir)
+}
@@ -0,0 +1,373 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+// newRelicenseCmd represents the relicense command
+func newRelicenseCmd() *cobra.Command {
+	relicenseCmd := &cobra.Command{
+		Use:   "relicense [project directory]",
+		Short: "migrate a project to another license.",
+		Long: `liquid relicense migrates the project in input directory (default is current directory) from its current license to the license specified by license flag.
+It rewrites LICENSE file, license header of .go files in the project and its subdirectories, NOTICE file and license section of README, and updates fixed license of config.
+Current license is detected from LICENSE file of the project unless from flag is specified. Headers of other copyright holders and headers of other licenses are kept, and files without license header are left as they are.
+Diff of planned changes is shown first, and nothing is written if dry-run flag is on. Files are written together: if writing some of them fails, the written files are restored. The run can be undone by liquid undo.`,
+		Args: usageArgs(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("license") && !cmd.Flags().Changed("customLicense") {
+				return usageErrorf("license flag is required to relicense")
+			}
+			config, license, author, _, err := ProcessArg(cmd, args)
+			if err != nil {
+				return err
+			}
+			ho, err := getHeaderOptions(cmd, author, config)
+			if err != nil {
+				return err
+			}
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				panic(err)
+			}
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				panic(err)
+			}
+			fromName, err := cmd.Flags().GetString("from")
+			if err != nil {
+				panic(err)
+			}
+
+			dir := "."
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			if !isExistDir(dir) {
+				return usageErrorf("%s is not a directory", dir)
+			}
+			from := tools.GetDirLicense(dir)
+			if fromName != "" {
+				from, err = tools.LookupOSSLicense(fromName)
+				if err != nil {
+					return err
+				}
+			}
+			if from == nil {
+				return usageErrorf("current license of %s cannot be detected from its LICENSE file. specify it with from flag", dir)
+			}
+
+			rp, err := newReporter(cmd, "relicense")
+			if err != nil {
+				return err
+			}
+			plan := PlanRelicense(dir, from, license, ho)
+			if len(plan.Errs) > 0 {
+				for _, err := range plan.Errs {
+					cmd.Println(err)
+				}
+				return failureError(nil, plan.Errs)
+			}
+			fix := config.License["fix"]
+			if fix != "" {
+				config.License["fix"] = config.License["last"]
+			}
+
+			if rp.format == "text" {
+				out := cmd.OutOrStdout()
+				plan.writeDiff(out, dir)
+				if fix != "" && fix != config.License["fix"] {
+					fmt.Fprintf(out, "config: fixed license is changed from %s to %s.\r\n", fix, config.License["fix"])
+				}
+			}
+
+			if !force && !dryRun {
+				g := newGitStatus()
+				for _, c := range plan.Changes {
+					dirty, err := g.isDirty(c.Path)
+					if err != nil {
+						return err
+					}
+					if dirty {
+						return fmt.Errorf("%s has uncommitted changes. commit them first, or use --force to relicense anyway", c.Path)
+					}
+				}
+			}
+
+			if dryRun {
+				for _, c := range plan.Changes {
+					rp.report(newFileReport(c.Path, ActionSkipped, c.Old).skip("dry run"))
+				}
+			} else {
+				opt := &tools.WriteOption{}
+				reports, err := plan.apply(opt)
+				if err != nil {
+					return err
+				}
+				for _, r := range reports {
+					rp.report(r)
+				}
+				if fix != "" {
+					configPath, err := getConfigPath(cmd)
+					if err == nil {
+						err = WriteConfigFile(config, configPath)
+					}
+					if err != nil {
+						cmd.Println("error occured in write config file")
+						cmd.Println(err)
+					}
+				}
+			}
+			for _, r := range plan.Skipped {
+				rp.report(r)
+			}
+			return rp.finish()
+		},
+	}
+
+	relicenseCmd.Flags().String("from", "", "name of current license of the project (default is license detected from LICENSE file of the project)")
+	relicenseCmd.Flags().Bool("dry-run", false, "If this flag is true, diff of planned changes is shown and no file is written.")
+	relicenseCmd.Flags().Bool("force", false, "If this flag is true, the project is relicensed even if planned files have uncommitted changes in git work tree.")
+
+	return relicenseCmd
+}
+
+//RelicensePlan is changes of files planned by relicense.
+type RelicensePlan struct {
+	//Changes are files to be written. Files that are not changed are not included.
+	Changes []RelicenseChange
+	//Skipped are reports of source files whose license header is kept.
+	Skipped []*FileReport
+	//Errs are errors occurred in planning. The plan must not be applied if it has errors.
+	Errs []error
+}
+
+//RelicenseChange is a file written by relicense.
+type RelicenseChange struct {
+	Path string
+	//Old is current content of the file. It is nil if the file is created.
+	Old []byte
+	New []byte
+	//License is license of new license header. It is nil for files other than source code.
+	License *tools.License
+}
+
+//noticeFiles and readmeFiles are candidates of NOTICE file and README of project.
+var (
+	noticeFiles = []string{"NOTICE", "NOTICE.txt", "NOTICE.md"}
+	readmeFiles = []string{"README.md", "README", "README.txt", "README.markdown"}
+)
+
+//PlanRelicense plans changes of files to migrate the project in dir from license from to license to with license header of ho.
+func PlanRelicense(dir string, from, to *tools.License, ho *tools.HeaderOptions) *RelicensePlan {
+	plan := &RelicensePlan{}
+	add := func(fp string, old, data []byte, l *tools.License) {
+		if old == nil || !bytes.Equal(old, data) {
+			plan.Changes = append(plan.Changes, RelicenseChange{fp, old, data, l})
+		}
+	}
+	//files other than source code are in dir.
+	fsys := os.DirFS(dir)
+	read := func(fp string) ([]byte, bool) {
+		b, err := fs.ReadFile(fsys, filepath.Base(fp))
+		if err != nil && !os.IsNotExist(err) {
+			plan.Errs = append(plan.Errs, rootError(dir, err))
+		}
+		return b, err == nil
+	}
+
+	lp := tools.FindLicenseFile(dir)
+	if lp == "" {
+		lp = filepath.Join(dir, "LICENSE")
+	}
+	text, err := to.LicenseText(ho)
+	if err != nil {
+		plan.Errs = append(plan.Errs, err)
+		return plan
+	}
+	if old, ok := read(lp); ok {
+		add(lp, old, []byte(text), nil)
+	} else {
+		add(lp, nil, []byte(text), nil)
+	}
+
+	notice := ""
+	for _, n := range noticeFiles {
+		if e, _ := tools.IsExistFile(filepath.Join(dir, n)); e {
+			notice = filepath.Join(dir, n)
+			break
+		}
+	}
+	if notice != "" {
+		if old, ok := read(notice); ok {
+			s, _ := tools.ReplaceLicenseName(string(old), from, to)
+			add(notice, old, []byte(s), nil)
+		}
+	} else if to.SPDX == "Apache-2.0" {
+		//NOTICE file is expected in projects licensed under Apache License.
+		s, err := tools.NoticeText(projectName(dir, ho), ho)
+		if err != nil {
+			plan.Errs = append(plan.Errs, err)
+		} else {
+			add(filepath.Join(dir, noticeFiles[0]), nil, []byte(s), nil)
+		}
+	}
+
+	for _, n := range readmeFiles {
+		fp := filepath.Join(dir, n)
+		if old, ok := read(fp); ok {
+			add(fp, old, []byte(tools.UpdateReadmeLicense(string(old), from, to, filepath.Base(lp))), nil)
+			break
+		}
+	}
+
+	targets, errs := listHeadTargets([]string{dir}, true, &licenseCache{def: to})
+	plan.Errs = append(plan.Errs, errs...)
+	for _, t := range targets {
+		src, err := t.read()
+		if err != nil {
+			plan.Errs = append(plan.Errs, err)
+			continue
+		}
+		data, skip, err := relicenseSource(t.path, src, from, to, ho)
+		switch {
+		case err != nil:
+			plan.Errs = append(plan.Errs, err)
+		case skip != "":
+			plan.Skipped = append(plan.Skipped, newFileReport(t.path, ActionSkipped, src).skip(skip))
+		default:
+			add(t.path, src, data, to)
+		}
+	}
+
+	return plan
+}
+
+//relicenseSource returns source code src of the file fp with license header of license to. If the header of src is not changed by relicense, the reason is returned as skip.
+func relicenseSource(fp string, src []byte, from, to *tools.License, ho *tools.HeaderOptions) (data []byte, skip string, err error) {
+	fho := ho.ForFile(fp)
+	status, _ := tools.CheckHeader(src, to, fho)
+	switch status {
+	case tools.HeaderMissing:
+		return nil, "it has no license header", nil
+	case tools.HeaderForeign:
+		return nil, "license header belongs to other copyright holder", nil
+	case tools.HeaderWrongLicense:
+		if s, _ := tools.CheckHeader(src, from, fho); s == tools.HeaderWrongLicense {
+			return nil, "license header is neither " + from.Name + " nor " + to.Name, nil
+		}
+	}
+	var b bytes.Buffer
+	err = writeFileHeader(&b, src, to, fho)
+	return b.Bytes(), "", err
+}
+
+//projectName returns name of the project in dir.
+func projectName(dir string, ho *tools.HeaderOptions) string {
+	if ho.Project != "" {
+		return ho.Project
+	}
+	if m := tools.ModulePath(dir); m != "" {
+		return path.Base(m)
+	}
+	if ad, err := filepath.Abs(dir); err == nil {
+		return filepath.Base(ad)
+	}
+	return ""
+}
+
+//writeDiff writes unified diff of changes in p to w. Paths in the diff are relative to dir.
+func (p *RelicensePlan) writeDiff(w io.Writer, dir string) {
+	for _, c := range p.Changes {
+		name := filepath.ToSlash(c.Path)
+		if rel, err := filepath.Rel(dir, c.Path); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+		oldName := "a/" + name
+		if c.Old == nil {
+			oldName = "/dev/null"
+		}
+		io.WriteString(w, tools.UnifiedDiff(oldName, "b/"+name, c.Old, c.New))
+	}
+}
+
+//apply writes changes of p according to opt, and returns their reports. Files are recorded to a journal, so the run can be undone.
+//If writing a file fails, files written before it are restored and error is returned.
+func (p *RelicensePlan) apply(opt *tools.WriteOption) ([]*FileReport, error) {
+	j, err := NewJournal("relicense")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create journal: %w", err)
+	}
+
+	reports := make([]*FileReport, 0, len(p.Changes))
+	for _, c := range p.Changes {
+		err := j.WriteFile(c.Path, c.New, opt)
+		if err != nil {
+			written := p.Changes[:len(reports)]
+			if rerr := rollback(written); rerr != nil {
+				j.Close()
+				return nil, fmt.Errorf("cannot write %s: %w. written files cannot be restored: %v", c.Path, err, rerr)
+			}
+			//nothing is left to undo.
+			os.RemoveAll(j.dir)
+			return nil, fmt.Errorf("cannot write %s, and written files are restored: %w", c.Path, err)
+		}
+
+		r := newFileReport(c.Path, ActionUpdated, c.Old)
+		if c.Old == nil {
+			r.Action = ActionCreated
+		}
+		if c.License != nil {
+			r.setNew(c.License, c.New)
+		}
+		reports = append(reports, r)
+	}
+	return reports, j.Close()
+}
+
+//rollback restores original contents of changes that are already written. Created files are removed.
+func rollback(changes []RelicenseChange) error {
+	var first error
+	for i := len(changes) - 1; i >= 0; i-- {
+		c := changes[i]
+		var err error
+		if c.Old == nil {
+			err = os.Remove(c.Path)
+		} else {
+			err = tools.WriteFileAtomic(c.Path, c.Old, nil)
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
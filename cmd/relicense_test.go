@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+func TestRelicense(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-relicense")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mit, apache := tools.OSSLicenses["mit"], tools.OSSLicenses["apache"]
+	ho := &tools.HeaderOptions{Author: "me", Year: 2019}
+	header := func(holder string, l *tools.License) string {
+		return "// Copyright (c) 2019 " + holder + "\n" + tools.CommentifyString(l.Header) + "\n\n"
+	}
+	own := filepath.Join(dir, "own.go")
+	foreign := filepath.Join(dir, "foreign.go")
+	missing := filepath.Join(dir, "missing.go")
+	vendored := filepath.Join(dir, "vendor", "v", "v.go")
+	readme := filepath.Join(dir, "README.md")
+	ioutil.WriteFile(own, []byte(header("me", mit)+"package a\n"), 0644)
+	ioutil.WriteFile(foreign, []byte(header("other", mit)+"package a\n"), 0644)
+	ioutil.WriteFile(missing, []byte("package a\n"), 0644)
+	os.MkdirAll(filepath.Dir(vendored), 0755)
+	ioutil.WriteFile(vendored, []byte(header("me", mit)+"package v\n"), 0644)
+	ioutil.WriteFile(readme, []byte("# a\n\n## License\n\n"+mit.Name+"\n"), 0644)
+
+	plan := PlanRelicense(dir, mit, apache, ho)
+	if len(plan.Errs) > 0 {
+		t.Fatal(plan.Errs)
+	}
+	skipped := make(map[string]bool)
+	for _, r := range plan.Skipped {
+		skipped[r.Path] = true
+	}
+	if len(plan.Skipped) != 2 || !skipped[foreign] || !skipped[missing] {
+		t.Errorf("header of other copyright holder and file without header must be kept: %+v", plan.Skipped)
+	}
+	changed := make(map[string]bool)
+	for _, c := range plan.Changes {
+		changed[filepath.Base(c.Path)] = true
+	}
+	if changed["v.go"] {
+		t.Error("vendor directory must not be relicensed")
+	}
+	for _, n := range []string{"LICENSE", "NOTICE", "README.md", "own.go"} {
+		if !changed[n] {
+			t.Errorf("%s is not planned to be changed: %v", n, changed)
+		}
+	}
+
+	_, err = plan.apply(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, _, _ := CheckFileHeader(own, apache, ho); status != tools.HeaderCorrect {
+		t.Errorf("header is not relicensed: %s", status)
+	}
+	if b, _ := ioutil.ReadFile(readme); !strings.Contains(string(b), apache.Name) {
+		t.Errorf("README is not relicensed: %s", b)
+	}
+	if l := tools.GetDirLicense(dir); l != apache {
+		t.Errorf("LICENSE is not relicensed: %v", l)
+	}
+
+	//written files are restored if writing fails.
+	src, _ := ioutil.ReadFile(own)
+	failing := &RelicensePlan{Changes: []RelicenseChange{
+		{Path: own, Old: src, New: []byte("package a\n")},
+		{Path: filepath.Join(dir, "none", "a.go"), New: []byte("package a\n")},
+	}}
+	if _, err := failing.apply(nil); err == nil {
+		t.Error("expected error in writing file to missing directory")
+	}
+	if b, _ := ioutil.ReadFile(own); string(b) != string(src) {
+		t.Errorf("written file is not restored: %s", b)
+	}
+}
+
+func TestRelicenseDryRun(t *testing.T) {
+	dir := newTestRepo(t)
+	defer os.RemoveAll(dir)
+	mit, _ := tools.GetOSSLicense("mit").LicenseText(&tools.HeaderOptions{Author: "me", Year: 2019})
+	src := "// Copyright (c) 2019 me\n" + tools.CommentifyString(tools.GetOSSLicense("mit").Header) + "\n\npackage a\n"
+	commitFiles(t, dir, map[string]string{"LICENSE": mit, "a.go": src})
+	//uncommitted changes do not matter to dry run, which writes nothing.
+	ioutil.WriteFile(filepath.Join(dir, "a.go"), []byte(src+"\nvar a int\n"), 0644)
+
+	cdir, err := ioutil.TempDir("", "liquid-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(cdir)
+	configPath := filepath.Join(cdir, "config.json")
+	config := NewConfig()
+	config.SetDefValue()
+	if err := WriteConfigFile(config, configPath); err != nil {
+		t.Fatal(err)
+	}
+	configData, _ := ioutil.ReadFile(configPath)
+
+	run := func(args ...string) error {
+		lcmd := newRootCmd()
+		lcmd.SetArgs(append([]string{"relicense", dir, "-l", "apache", "-a", "me", "--year", "2019", "--config", configPath}, args...))
+		lcmd.SetOut(ioutil.Discard)
+		lcmd.SetErr(ioutil.Discard)
+		return lcmd.Execute()
+	}
+	if err := run("--dry-run"); err != nil {
+		t.Errorf("dry run must not be refused for uncommitted changes: %v", err)
+	}
+	if b, _ := ioutil.ReadFile(filepath.Join(dir, "LICENSE")); string(b) != mit {
+		t.Error("dry run must not write files")
+	}
+	if b, _ := ioutil.ReadFile(configPath); string(b) != string(configData) {
+		t.Errorf("dry run must not write config: %s", b)
+	}
+	if err := run(); err == nil || !strings.Contains(err.Error(), "uncommitted changes") {
+		t.Errorf("relicense of project that has uncommitted changes must be refused: %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+//Actions of FileReport.
+const (
+	ActionCreated     = "created"
+	ActionUpdated     = "updated"
+	ActionStripped    = "stripped"
+	ActionUnchanged   = "unchanged"
+	ActionSkipped     = "skipped"
+	ActionFailed      = "failed"
+	ActionChecked     = "checked"
+	ActionRestored    = "restored"
+	ActionRemoved     = "removed"
+	ActionInstalled   = "installed"
+	ActionUninstalled = "uninstalled"
+)
+
+//actionOrder is order of actions in text summary.
+var actionOrder = []string{ActionCreated, ActionUpdated, ActionStripped, ActionUnchanged, ActionChecked, ActionRestored, ActionRemoved, ActionInstalled, ActionUninstalled, ActionSkipped, ActionFailed}
+
+//FileReport is a record of a file processed by liquid.
+type FileReport struct {
+	Type   string `json:"type"`
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	//Status is status of license header. It is set by check.
+	Status     string   `json:"status,omitempty"`
+	OldLicense string   `json:"oldLicense,omitempty"`
+	NewLicense string   `json:"newLicense,omitempty"`
+	OldHolders []string `json:"oldHolders,omitempty"`
+	NewHolders []string `json:"newHolders,omitempty"`
+	OldYears   string   `json:"oldYears,omitempty"`
+	NewYears   string   `json:"newYears,omitempty"`
+	//Reason is why the file is skipped.
+	Reason string `json:"reason,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	//Err is error occurred in processing the file.
+	Err error `json:"-"`
+	//header is parsed license header of the file before processing.
+	header *tools.HeaderInfo
+	//status is status of license header checked by check.
+	status tools.HeaderStatus
+	//expected is expected license header of the file checked by check.
+	expected string
+}
+
+//newFileReport creates FileReport of fp whose original content is src. src can be nil if the file does not exist.
+func newFileReport(fp string, action string, src []byte) *FileReport {
+	r := &FileReport{Type: "file", Path: fp, Action: action}
+	if src != nil {
+		r.setOld(tools.ParseHeader(src))
+	}
+	return r
+}
+
+//setOld sets information of license header before processing.
+func (r *FileReport) setOld(info *tools.HeaderInfo) {
+	r.header = info
+	if info == nil {
+		return
+	}
+	r.OldLicense = licenseName(tools.DetectLicense(info.Text))
+	r.OldHolders = splitHolders(info.Holder)
+	r.OldYears = info.Years
+}
+
+//setNew sets information of license header written by liquid.
+func (r *FileReport) setNew(l *tools.License, src []byte) {
+	r.NewLicense = licenseName(l)
+	if info := tools.ParseHeader(src); info != nil {
+		r.NewHolders = splitHolders(info.Holder)
+		r.NewYears = info.Years
+	}
+}
+
+//fail sets err to r.
+func (r *FileReport) fail(err error) *FileReport {
+	r.Action = ActionFailed
+	r.Err = err
+	r.Error = err.Error()
+	return r
+}
+
+//setStatus sets status of license header checked by check.
+func (r *FileReport) setStatus(status tools.HeaderStatus) {
+	r.status = status
+	r.Status = status.String()
+}
+
+//skip marks r as skipped because of reason.
+func (r *FileReport) skip(reason string) *FileReport {
+	r.Action = ActionSkipped
+	r.Reason = reason
+	return r
+}
+
+func licenseName(l *tools.License) string {
+	if l == nil {
+		return "unknown"
+	}
+	return l.Name
+}
+
+//splitHolders splits copyright holders written like "A, B and C".
+func splitHolders(holder string) []string {
+	return tools.SplitHolders(holder)
+}
+
+//Summary is summary of FileReports of a run of liquid command.
+type Summary struct {
+	Type    string `json:"type"`
+	Command string `json:"command"`
+	Files   int    `json:"files"`
+	//Actions is the number of files for each action.
+	Actions map[string]int `json:"actions"`
+	//Statuses is the number of files for each status of license header. It is set by check.
+	Statuses map[string]int `json:"statuses,omitempty"`
+}
+
+//reporter outputs FileReports and Summary in the format specified by user.
+type reporter struct {
+	format  string
+	w       io.Writer
+	reports []*FileReport
+	summary *Summary
+	//junitGroup is how test cases are grouped into test suites in junit format.
+	junitGroup string
+}
+
+//reportFormats are formats supported by all commands.
+var reportFormats = []string{"text", "json", "ndjson"}
+
+//addFormatFlag adds format flag to c.
+func addFormatFlag(c *cobra.Command) {
+	c.PersistentFlags().String("format", "text", "output format: "+strings.Join(reportFormats, ", "))
+}
+
+//newReporter creates reporter of command c from its format flag. formats are formats supported by the command in addition to reportFormats.
+func newReporter(c *cobra.Command, command string, formats ...string) (*reporter, error) {
+	format, err := c.Flags().GetString("format")
+	if err != nil {
+		panic(err)
+	}
+	supported := false
+	for _, f := range append(reportFormats, formats...) {
+		supported = supported || f == format
+	}
+	if !supported {
+		return nil, usageErrorf("%s command does not support format %s", command, format)
+	}
+
+	rp := newTextReporter(c.OutOrStdout(), command)
+	rp.format = format
+	return rp, nil
+}
+
+//newTextReporter creates reporter that outputs human readable messages to w.
+func newTextReporter(w io.Writer, command string) *reporter {
+	return &reporter{
+		format:  "text",
+		w:       w,
+		summary: &Summary{Type: "summary", Command: command, Actions: make(map[string]int)},
+	}
+}
+
+//report outputs r. Reports of json format are kept until finish is called.
+func (rp *reporter) report(r *FileReport) {
+	rp.add(r)
+
+	switch rp.format {
+	case "text":
+		rp.text(r)
+	case "ndjson":
+		rp.ndjson(r)
+	}
+}
+
+//add records r and counts it in summary.
+func (rp *reporter) add(r *FileReport) {
+	rp.reports = append(rp.reports, r)
+	rp.summary.Files++
+	rp.summary.Actions[r.Action]++
+	if r.Status != "" {
+		if rp.summary.Statuses == nil {
+			rp.summary.Statuses = make(map[string]int)
+		}
+		rp.summary.Statuses[r.Status]++
+	}
+}
+
+func (rp *reporter) text(r *FileReport) {
+	switch r.Action {
+	case ActionCreated:
+		fmt.Fprintf(rp.w, "created: %s\r\n", r.Path)
+	case ActionUpdated:
+		fmt.Fprintln(rp.w, "added license header to ", r.Path, ".")
+	case ActionStripped:
+		fmt.Fprintf(rp.w, "removed license header from %s.\r\n", r.Path)
+	case ActionUnchanged:
+		fmt.Fprintf(rp.w, "license header of %s is up to date.\r\n", r.Path)
+	case ActionSkipped:
+		fmt.Fprintf(rp.w, "skipped %s: %s\r\n", r.Path, r.Reason)
+	case ActionFailed:
+		fmt.Fprintf(rp.w, "%s: %s\r\n", r.Path, r.Error)
+	case ActionChecked:
+		if r.status != tools.HeaderCorrect {
+			fmt.Fprintf(rp.w, "%s: %s\r\n", r.Path, r.status.Description())
+		}
+	default:
+		fmt.Fprintln(rp.w, r.Action, r.Path)
+	}
+}
+
+func (rp *reporter) ndjson(v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	rp.w.Write(append(b, '\n'))
+}
+
+//problems returns the number of reported files that failed or have problems in license header.
+func (rp *reporter) problems() int {
+	n := rp.summary.Actions[ActionFailed]
+	for s, c := range rp.summary.Statuses {
+		if s != tools.HeaderCorrect.String() {
+			n += c
+		}
+	}
+	return n
+}
+
+//violations returns the number of reported files that have problems in license header.
+func (rp *reporter) violations() int {
+	return rp.problems() - rp.summary.Actions[ActionFailed]
+}
+
+//fault reports err that occurred before processing files, such as input path that cannot be read, as a failed file.
+//Path of the report is the path in err, or empty if err has no path. In text format it is not output again, because commands print err when it occurs.
+func (rp *reporter) fault(err error) {
+	r := (&FileReport{Type: "file"}).fail(err)
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		r.Path = pe.Path
+	}
+	if rp.format == "text" {
+		rp.add(r)
+		return
+	}
+	rp.report(r)
+}
+
+//err returns error that represents failures reported to rp.
+func (rp *reporter) err() error {
+	return failureError(rp.reports, nil)
+}
+
+//failureError returns error that represents failed reports and errs. If every file and input failed, the first error is returned. If some of them failed, PartialError is returned.
+func failureError(reports []*FileReport, errs []error) error {
+	failed := len(errs)
+	var first error
+	if len(errs) > 0 {
+		first = errs[0]
+	}
+	for _, r := range reports {
+		if r.Action == ActionFailed {
+			failed++
+			if first == nil {
+				first = r.Err
+			}
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	if total := len(reports) + len(errs); failed < total {
+		return &PartialError{Failed: failed, Total: total}
+	}
+	return first
+}
+
+//finish outputs summary of reports.
+func (rp *reporter) finish() error {
+	s := rp.summary
+	switch rp.format {
+	case "json":
+		if rp.reports == nil {
+			rp.reports = []*FileReport{}
+		}
+		b, err := json.MarshalIndent(struct {
+			Files   []*FileReport `json:"files"`
+			Summary *Summary      `json:"summary"`
+		}{rp.reports, s}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = rp.w.Write(append(b, '\n'))
+		return err
+	case "ndjson":
+		rp.ndjson(s)
+		return nil
+	case "sarif":
+		return writeSARIF(rp.w, rp.reports)
+	case "junit":
+		return writeJUnit(rp.w, rp.reports, rp.junitGroup)
+	}
+
+	if s.Command == "check" {
+		p := rp.problems()
+		fmt.Fprintf(rp.w, "%d files checked: %d correct, %d problems.\r\n", s.Files, s.Files-p, p)
+		return nil
+	}
+	var actions []string
+	for _, a := range actionOrder {
+		if n := s.Actions[a]; n > 0 {
+			actions = append(actions, fmt.Sprintf("%d %s", n, a))
+		}
+	}
+	fmt.Fprintf(rp.w, "%d files processed: %s.\r\n", s.Files, strings.Join(actions, ", "))
+	return nil
+}
+
+//relPath returns slash separated path of fp relative to current directory. If fp is out of current directory, its absolute path is returned.
+func relPath(fp string) string {
+	if !filepath.IsAbs(fp) {
+		return filepath.ToSlash(fp)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, fp); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(fp)
+}
@@ -25,7 +25,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/suquiya/liquid/tools"
@@ -37,53 +41,49 @@ import (
 type Config struct {
 	License map[string]string `json:"license"`
 	Author  map[string]string `json:"author"`
+	//Header is format of license header. Its "copyright" is template of copyright line, "template" is header template, "templateFile" is file of header template, "project" is project name and "width" is width that header text is reflowed to.
+	Header map[string]string `json:"header"`
+	//Style is comment style of license header for each language: "line" for "//" lines, "block" for "/* */" block or "doc" for "/** */" block.
+	//Its keys are languages such as "go" or file extensions such as ".go", and "default" is style of the other languages.
+	Style map[string]string `json:"style"`
 }
 
 //Record write config c as json to a file specified by p
-func Record(c *Config, p string) {
+func Record(c *Config, p string) error {
 	j, err := json.Marshal(c)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	f, err := os.Create(p)
+	err = ioutil.WriteFile(p, j, 0644)
 	if err != nil {
-		fmt.Println("Cannot create config file. Specified license of author will not record.")
+		return &tools.IOError{Op: "record config to", Path: p, Err: err}
 	}
-	defer f.Close()
-	f.Write(j)
-
+	return nil
 }
 
-//ReadConfigFile read config from file locating p
-func ReadConfigFile(p string) *Config {
-	f, err := os.Open(p)
-	defer f.Close()
+//ReadConfigFile read config from file locating p. If the file does not exist, it returns nil config without error.
+func ReadConfigFile(p string) (*Config, error) {
+	b, err := ioutil.ReadFile(p)
 	if os.IsNotExist(err) {
-		return nil
+		return nil, nil
 	}
 	if err != nil {
-		fmt.Println(err)
-		return nil
+		return nil, &tools.IOError{Op: "read config", Path: p, Err: err}
 	}
 
-	b, err := ioutil.ReadAll(f)
-	if err != nil {
-		return nil
-	}
 	c := NewConfig()
 	err = json.Unmarshal(b, c)
 	if err != nil {
-		fmt.Println(err)
-		return nil
+		return nil, fmt.Errorf("config file %s is broken: %w", p, err)
 	}
 
-	return c
+	return c, nil
 }
 
 //NewConfig crate new instance of config.
 func NewConfig() *Config {
-	return &Config{make(map[string]string), make(map[string]string)}
+	return &Config{make(map[string]string), make(map[string]string), make(map[string]string), make(map[string]string)}
 }
 
 //SetDefValue set default vaule
@@ -94,6 +94,13 @@ func (c *Config) SetDefValue() {
 	c.License["customTextFile"] = ""
 	c.Author["last"] = "COPYRIGHT HOLDER"
 	c.Author["fix"] = ""
+	c.Author["email"] = ""
+	c.Header["copyright"] = ""
+	c.Header["template"] = ""
+	c.Header["templateFile"] = ""
+	c.Header["project"] = ""
+	c.Header["width"] = ""
+	c.Style["default"] = ""
 }
 
 //GetLicenseValue get license value
@@ -115,7 +122,6 @@ func (c *Config) GetAuthorValue() string {
 func getDefaultConfigPath() string {
 	home, err := homedir.Dir()
 	if err != nil {
-		fmt.Println(err)
 		return ""
 	}
 	return filepath.Join(home, ".config_liquid.json")
@@ -128,6 +134,7 @@ func newRootCmd() *cobra.Command {
 		Use:   "liquid",
 		Short: "liquid is utility for license management in golang.",
 		Long: `liquid is utility for license management in golang. liquid can add LICENSE to top of source code, and replace its LICENSE to another LICENSE.
+Exit status is 0 on success, 1 on other errors, 2 on wrong flags or arguments, 3 on failure in reading or writing files, 4 if check finds problems in license header and 5 if some of files failed.
 		`,
 		// Uncomment the following line if your bare application
 		// has an action associated with it:
@@ -137,13 +144,35 @@ func newRootCmd() *cobra.Command {
 			},
 		*/
 		//RunE: Process,
+		//errors are written by Execute with exit code for them.
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
+	rootCmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return &UsageError{err}
+	})
 
 	rootCmd.AddCommand(newAddCmd())
 	rootCmd.AddCommand(newHeadCmd())
-
+	rootCmd.AddCommand(newCheckCmd())
+	rootCmd.AddCommand(newUndoCmd())
+	rootCmd.AddCommand(newHookCmd())
+	rootCmd.AddCommand(newReportCmd())
+	rootCmd.AddCommand(newStatsCmd())
+	rootCmd.AddCommand(newStripCmd())
+	rootCmd.AddCommand(newRelicenseCmd())
+	rootCmd.AddCommand(newBumpYearCmd())
+
+	addFormatFlag(rootCmd)
 	rootCmd.PersistentFlags().StringP("license", "l", "mit", "name of license (first default is mit or license that is detected from directory's LICENSE file. And after first use, config record what user choose and set it as \"mit\" position in default)")
 	rootCmd.PersistentFlags().StringP("author", "a", "COPYRIGHT HOLDER", "author(copyright holder) name for copyright (default is COPYTIGHT HOLDER)")
+	rootCmd.PersistentFlags().String("copyright-format", "", "text/template of copyright line, in which {{.Year}}, {{.Holder}} and {{.Email}} can be used (default is \"header.copyright\" of config or \""+tools.DefaultCopyrightFormat+"\")")
+	rootCmd.PersistentFlags().String("header-template", "", "file of text/template of license header (default is \"header.templateFile\" or \"header.template\" of config). Variables such as {{.Copyright}}, {{.YearRange}}, {{.Holders}}, {{.Project}}, {{.Module}}, {{.File}}, {{.SPDX}} and {{.LicenseURL}} and functions wrap, upper, lower and join can be used.")
+	rootCmd.PersistentFlags().String("project", "", "project name used in header template (default is \"header.project\" of config or last element of module path)")
+	rootCmd.PersistentFlags().Int("width", 0, "reflow header text so that header lines including comment marks are not longer than width. 0 means header text is not reflowed (default is \"header.width\" of config)")
+	rootCmd.PersistentFlags().String("comment-style", "", "comment style of license header of every language: line, block or doc. Existing header is converted to the style by sethead (default is \"style\" of config or line)")
+	rootCmd.PersistentFlags().String("email", "", "email address of author used in copyright line (default is \"author.email\" of config)")
+	rootCmd.PersistentFlags().Int("year", 0, "copyright year of license header (default is year of SOURCE_DATE_EPOCH environment variable if it is set, or current year)")
 	rootCmd.PersistentFlags().BoolP("customLicense", "c", false, "Ir use custom license, turn on this flag.")
 	rootCmd.PersistentFlags().String("config", "", "config file. Default is "+getDefaultConfigPath())
 	rootCmd.PersistentFlags().String("Header", "", "file path of custom license header. This flag cannot be use without customLicense flag on.")
@@ -152,18 +181,17 @@ func newRootCmd() *cobra.Command {
 }
 
 //ProcessArg process args to get license,author and config data from arg and config file.
-func ProcessArg(cmd *cobra.Command, args []string) (*Config, *tools.License, string, bool) {
-	configPath, err := cmd.Flags().GetString("config")
+func ProcessArg(cmd *cobra.Command, args []string) (*Config, *tools.License, string, bool, error) {
+	configPath, err := getConfigPath(cmd)
 	if err != nil {
-		panic(err)
-	}
-
-	if exist, _ := tools.IsExistFilePath(configPath); !exist {
-		configPath = getDefaultConfigPath()
+		return nil, nil, "", false, err
 	}
 
 	cmd.Println("read configfile:", configPath)
-	config := ReadConfigFile(configPath)
+	config, err := ReadConfigFile(configPath)
+	if err != nil {
+		return nil, nil, "", false, err
+	}
 	cmd.Println("finish reading config file.")
 	if config == nil {
 		config = NewConfig()
@@ -172,12 +200,11 @@ func ProcessArg(cmd *cobra.Command, args []string) (*Config, *tools.License, str
 
 	l, err := cmd.Flags().GetString("license")
 	if err != nil {
-		panic(err)
+		return nil, nil, "", false, err
 	}
 	c, err := cmd.Flags().GetBool("customLicense")
-
 	if err != nil {
-		panic(err)
+		return nil, nil, "", false, err
 	}
 
 	licenseName := ""
@@ -192,37 +219,202 @@ func ProcessArg(cmd *cobra.Command, args []string) (*Config, *tools.License, str
 	if licenseName == "custom" {
 		h, err := cmd.Flags().GetString("Header")
 		if err != nil {
-			panic(err)
+			return nil, nil, "", false, err
 		}
 		t, err := cmd.Flags().GetString("Text")
 		if err != nil {
-			panic(err)
+			return nil, nil, "", false, err
 		}
 
 		headPath, textPath := getHeader(h, config), getText(t, config)
 		license, err = tools.CreateCustomLicense(headPath, textPath)
 		if err != nil {
-			fmt.Println(err)
-			license = tools.GetOSSLicense("mit")
+			return nil, nil, "", false, err
 		}
-
 	} else {
-		license = tools.GetOSSLicense(licenseName)
+		license, err = tools.LookupOSSLicense(licenseName)
+		if err != nil {
+			return nil, nil, "", false, err
+		}
 	}
 	a, err := cmd.Flags().GetString("author")
 	if err != nil {
-		panic(err)
+		return nil, nil, "", false, err
 	}
 
 	config.License["last"] = licenseName
 	config.Author["last"] = getAuthor(a, config)
 
+	//config is not recorded in dry run, which writes nothing.
+	if f := cmd.Flags().Lookup("dry-run"); f != nil && f.Value.String() == "true" {
+		return config, license, config.Author["last"], licenseIsNotSet, nil
+	}
+	//failure of recording config does not stop the command.
 	err = WriteConfigFile(config, configPath)
 	if err != nil {
 		cmd.Println("error occured in write config file")
 		cmd.Println(err)
 	}
-	return config, license, config.Author["last"], licenseIsNotSet
+	return config, license, config.Author["last"], licenseIsNotSet, nil
+}
+
+//getConfigPath returns path of config file from config flag of cmd. If the file does not exist, default path is returned.
+func getConfigPath(cmd *cobra.Command) (string, error) {
+	configPath, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return "", err
+	}
+	if exist, _ := tools.IsExistFilePath(configPath); !exist {
+		configPath = getDefaultConfigPath()
+	}
+	return configPath, nil
+}
+
+//getHeaderOptions returns options of license header of author from flags of cmd and config.
+func getHeaderOptions(cmd *cobra.Command, author string, config *Config) (*tools.HeaderOptions, error) {
+	year, err := getYear(cmd)
+	if err != nil {
+		return nil, err
+	}
+	format, err := cmd.Flags().GetString("copyright-format")
+	if err != nil {
+		panic(err)
+	}
+	if format == "" {
+		format = config.Header["copyright"]
+	}
+	if err := tools.ValidateCopyrightFormat(format); err != nil {
+		return nil, usageErrorf("invalid copyright format: %s", err)
+	}
+	email, err := cmd.Flags().GetString("email")
+	if err != nil {
+		panic(err)
+	}
+	if email == "" {
+		email = config.Author["email"]
+	}
+	tmpl, err := getHeaderTemplate(cmd, config)
+	if err != nil {
+		return nil, err
+	}
+	project, err := cmd.Flags().GetString("project")
+	if err != nil {
+		panic(err)
+	}
+	if project == "" {
+		project = config.Header["project"]
+	}
+	width, err := getWidth(cmd, config)
+	if err != nil {
+		return nil, err
+	}
+	style, styles, err := getCommentStyles(cmd, config)
+	if err != nil {
+		return nil, err
+	}
+	return &tools.HeaderOptions{Author: author, Email: email, Year: year, CopyrightFormat: format, Template: tmpl, Project: project, Width: width, Style: style, Styles: styles}, nil
+}
+
+//getCommentStyles returns default comment style of license header and comment styles of file extensions from comment-style flag of cmd or config.
+func getCommentStyles(cmd *cobra.Command, config *Config) (tools.CommentType, map[string]tools.CommentType, error) {
+	name, err := cmd.Flags().GetString("comment-style")
+	if err != nil {
+		panic(err)
+	}
+	if name != "" {
+		ct, err := tools.ParseCommentStyle(name)
+		if err != nil {
+			return tools.Lines, nil, usageErrorf("invalid comment style: %s", err)
+		}
+		return ct, nil, nil
+	}
+
+	style := tools.Lines
+	styles := make(map[string]tools.CommentType)
+	for lang, name := range config.Style {
+		if name == "" {
+			continue
+		}
+		ct, err := tools.ParseCommentStyle(name)
+		if err != nil {
+			return tools.Lines, nil, usageErrorf("invalid comment style of %s in config: %s", lang, err)
+		}
+		switch {
+		case lang == "default":
+			style = ct
+		case strings.HasPrefix(lang, "."):
+			styles[lang] = ct
+		default:
+			exts := extensionsOf(lang)
+			if len(exts) == 0 {
+				return tools.Lines, nil, usageErrorf("unknown language in style of config: %s", lang)
+			}
+			for _, ext := range exts {
+				styles[ext] = ct
+			}
+		}
+	}
+	return style, styles, nil
+}
+
+//getWidth returns width that header text is reflowed to from width flag of cmd or config.
+func getWidth(cmd *cobra.Command, config *Config) (int, error) {
+	width, err := cmd.Flags().GetInt("width")
+	if err != nil {
+		panic(err)
+	}
+	if !cmd.Flags().Changed("width") && config.Header["width"] != "" {
+		width, err = strconv.Atoi(config.Header["width"])
+		if err != nil {
+			return 0, usageErrorf("invalid width in config: %s", config.Header["width"])
+		}
+	}
+	if width < 0 {
+		return 0, usageErrorf("invalid width: %d", width)
+	}
+	return width, nil
+}
+
+//getHeaderTemplate returns header template from header-template flag of cmd or config.
+func getHeaderTemplate(cmd *cobra.Command, config *Config) (string, error) {
+	fp, err := cmd.Flags().GetString("header-template")
+	if err != nil {
+		panic(err)
+	}
+	if fp == "" {
+		fp = config.Header["templateFile"]
+	}
+	tmpl := config.Header["template"]
+	if fp != "" {
+		b, err := ioutil.ReadFile(fp)
+		if err != nil {
+			return "", &tools.IOError{Op: "read header template", Path: fp, Err: err}
+		}
+		tmpl = string(b)
+	}
+	if err := tools.ValidateHeaderTemplate(tmpl); err != nil {
+		return "", usageErrorf("invalid header template: %s", err)
+	}
+	return tmpl, nil
+}
+
+//getYear returns copyright year from year flag of cmd, SOURCE_DATE_EPOCH environment variable or current time.
+func getYear(cmd *cobra.Command) (int, error) {
+	year, err := cmd.Flags().GetInt("year")
+	if err != nil {
+		panic(err)
+	}
+	if year < 0 {
+		return 0, usageErrorf("invalid year: %d", year)
+	}
+	if year > 0 {
+		return year, nil
+	}
+	year, err = tools.CopyrightYear(nil)
+	if err != nil {
+		return 0, &UsageError{err}
+	}
+	return year, nil
 }
 
 //WriteConfigFile output config to configPath with JSON format.
@@ -276,9 +468,34 @@ func getAuthor(a string, c *Config) string {
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	if err := newRootCmd().Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	stop := handleInterrupt()
+	err := newRootCmd().Execute()
+	stop()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitCode(err))
+	}
+}
+
+//handleInterrupt removes temporary files being written when the process receives SIGINT or SIGTERM, and exits with status 130 or 143. Returned function stops handling.
+func handleInterrupt() func() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case s := <-c:
+			tools.RemoveTempFiles()
+			if s == os.Interrupt {
+				os.Exit(130)
+			}
+			os.Exit(143)
+		case <-done:
+		}
+	}()
+	return func() {
+		signal.Stop(c)
+		close(done)
 	}
 }
 
@@ -0,0 +1,210 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+//Types of SARIF 2.1.0 log. Only properties used by liquid are defined.
+type (
+	sarifLog struct {
+		Schema  string     `json:"$schema"`
+		Version string     `json:"version"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool               sarifTool                        `json:"tool"`
+		OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+		Invocations        []sarifInvocation                `json:"invocations"`
+		Results            []sarifResult                    `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID                   string             `json:"id"`
+		Name                 string             `json:"name"`
+		ShortDescription     sarifMessage       `json:"shortDescription"`
+		DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	}
+	sarifConfiguration struct {
+		Level string `json:"level"`
+	}
+	sarifInvocation struct {
+		ExecutionSuccessful        bool                `json:"executionSuccessful"`
+		ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+	}
+	sarifNotification struct {
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations,omitempty"`
+	}
+	sarifResult struct {
+		RuleID    string          `json:"ruleId"`
+		RuleIndex int             `json:"ruleIndex"`
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI       string `json:"uri"`
+		URIBaseID string `json:"uriBaseId,omitempty"`
+	}
+	sarifRegion struct {
+		StartLine int `json:"startLine"`
+		EndLine   int `json:"endLine"`
+	}
+)
+
+//sarifRules are rules of problems found by check. ID of a rule is string of its HeaderStatus.
+var sarifRules = []struct {
+	status tools.HeaderStatus
+	name   string
+	level  string
+}{
+	{tools.HeaderMissing, "MissingHeader", "error"},
+	{tools.HeaderWrongLicense, "WrongLicense", "error"},
+	{tools.HeaderStaleYear, "StaleYear", "warning"},
+	{tools.HeaderPlaceholderHolder, "PlaceholderHolder", "warning"},
+	{tools.HeaderForeign, "ForeignHeader", "note"},
+}
+
+//sarifSourceRoot is URI base id of files relative to current directory.
+const sarifSourceRoot = "SRCROOT"
+
+//sarifRuleIndex returns index of the rule of status in sarifRules. If status has no rule, it returns -1.
+func sarifRuleIndex(status tools.HeaderStatus) int {
+	for i, r := range sarifRules {
+		if r.status == status {
+			return i
+		}
+	}
+	return -1
+}
+
+//writeSARIF writes reports of check to w as SARIF 2.1.0 log.
+func writeSARIF(w io.Writer, reports []*FileReport) error {
+	driver := sarifDriver{Name: "liquid", InformationURI: "https://github.com/suquiya/liquid"}
+	for _, r := range sarifRules {
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   r.status.String(),
+			Name:                 r.name,
+			ShortDescription:     sarifMessage{r.status.Description()},
+			DefaultConfiguration: sarifConfiguration{r.level},
+		})
+	}
+
+	run := sarifRun{Tool: sarifTool{driver}, Results: []sarifResult{}}
+	if wd, err := os.Getwd(); err == nil {
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{sarifSourceRoot: {URI: fileURI(wd) + "/"}}
+	}
+	inv := sarifInvocation{ExecutionSuccessful: true}
+	for _, r := range reports {
+		if r.Action == ActionSkipped {
+			continue
+		}
+		loc := sarifLocation{sarifPhysicalLocation{ArtifactLocation: sarifArtifact(r.Path)}}
+		if r.Err != nil {
+			inv.ExecutionSuccessful = false
+			n := sarifNotification{Level: "error", Message: sarifMessage{r.Error}}
+			//errors of input like glob pattern have no file.
+			if r.Path != "" {
+				n.Locations = []sarifLocation{loc}
+			}
+			inv.ToolExecutionNotifications = append(inv.ToolExecutionNotifications, n)
+			continue
+		}
+		if r.status == tools.HeaderCorrect {
+			continue
+		}
+
+		i := sarifRuleIndex(r.status)
+		if i < 0 {
+			continue
+		}
+		region := &sarifRegion{1, 1}
+		if r.header != nil {
+			region = &sarifRegion{r.header.StartLine, r.header.EndLine}
+		}
+		loc.PhysicalLocation.Region = region
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    sarifRules[i].status.String(),
+			RuleIndex: i,
+			Level:     sarifRules[i].level,
+			Message:   sarifMessage{r.status.Description() + ". expected " + r.NewLicense + " header of " + strings.Join(r.NewHolders, ", ") + "."},
+			Locations: []sarifLocation{loc},
+		})
+	}
+	run.Invocations = []sarifInvocation{inv}
+
+	b, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+//sarifArtifact returns location of fp. Path in current directory is relative to SRCROOT, and path out of current directory is absolute file URI.
+func sarifArtifact(fp string) sarifArtifactLocation {
+	p := relPath(fp)
+	if filepath.IsAbs(filepath.FromSlash(p)) {
+		return sarifArtifactLocation{URI: fileURI(fp)}
+	}
+	return sarifArtifactLocation{URI: (&url.URL{Path: p}).String(), URIBaseID: sarifSourceRoot}
+}
+
+//fileURI returns file URI of absolute path p.
+func fileURI(p string) string {
+	p = filepath.ToSlash(p)
+	//Windows path like C:/a needs leading slash.
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
+}
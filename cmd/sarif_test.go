@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	wd, _ := os.Getwd()
+	report := func(fp string, status tools.HeaderStatus) *FileReport {
+		r := newFileReport(fp, ActionChecked, nil)
+		r.setStatus(status)
+		return r
+	}
+	reports := []*FileReport{
+		report(filepath.Join(wd, "a b.go"), tools.HeaderStaleYear),
+		report(filepath.Join(wd, "ok.go"), tools.HeaderCorrect),
+		report("/out/of/wd.go", tools.HeaderForeign),
+		newFileReport("broken.go", ActionFailed, nil).fail(errors.New("broken")),
+	}
+
+	var b bytes.Buffer
+	if err := writeSARIF(&b, reports); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(b.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+
+	if len(run.Results) != 2 {
+		t.Fatalf("results must have files that have problems: %+v", run.Results)
+	}
+	for _, r := range run.Results {
+		if rule := run.Tool.Driver.Rules[r.RuleIndex]; rule.ID != r.RuleID {
+			t.Errorf("rule index %d of %s points to rule %s", r.RuleIndex, r.RuleID, rule.ID)
+		}
+	}
+	if r := run.Results[0]; r.RuleID != tools.HeaderStaleYear.String() || r.Level != "warning" {
+		t.Errorf("unexpected result of stale year: %+v", r)
+	}
+
+	if a := run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation; a.URI != "a%20b.go" || a.URIBaseID != sarifSourceRoot {
+		t.Errorf("file in current directory must be relative to %s: %+v", sarifSourceRoot, a)
+	}
+	if base := run.OriginalURIBaseIDs[sarifSourceRoot].URI; base != fileURI(wd)+"/" {
+		t.Errorf("unexpected %s: %s", sarifSourceRoot, base)
+	}
+	if a := run.Results[1].Locations[0].PhysicalLocation.ArtifactLocation; a.URI != "file:///out/of/wd.go" || a.URIBaseID != "" {
+		t.Errorf("file out of current directory must be absolute file URI: %+v", a)
+	}
+
+	inv := run.Invocations[0]
+	if inv.ExecutionSuccessful || len(inv.ToolExecutionNotifications) != 1 {
+		t.Errorf("failed file must be reported as notification: %+v", inv)
+	}
+}
@@ -21,15 +21,14 @@
 package cmd
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/header"
 	"github.com/suquiya/liquid/tools"
 )
 
@@ -39,236 +38,194 @@ func newHeadCmd() *cobra.Command {
 	headCmd := &cobra.Command{
 		Use:   "sethead [Paths of files or directories]",
 		Short: "add license header to .go files in input directory or specified files.",
-		Long:  `liquid head add header to .go files in input directory or  input specified files. If user specified files already have license header, liquid change header to specified license.`,
-		Run: func(cmd *cobra.Command, args []string) {
-			config, license, author, LIsNotSet := ProcessArg(cmd, args)
-			var input []string
-			if cmd.Flags().NArg() < 1 {
-				input = make([]string, 1, 1)
-				var err error
-				input[0], err = os.Getwd()
+		Long: `liquid head add header to .go files in input directory or  input specified files. If user specified files already have license header, liquid change header to specified license.
+If input is "-", liquid reads source code from stdin and writes it with license header to stdout.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, license, author, LIsNotSet, err := ProcessArg(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			ho, err := getHeaderOptions(cmd, author, config)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 1 && args[0] == "-" {
+				fn, err := cmd.Flags().GetString("filename")
 				if err != nil {
-					cmd.Println("input is empty and current directory cannnot be gotten.")
-					cmd.Println(err)
+					panic(err)
 				}
-			} else {
-				input = cmd.Flags().Args()
+				lc := newLicenseCache(license, LIsNotSet, config)
+				if fn != "" {
+					ho = ho.ForFile(fn)
+				}
+				return FilterHeader(cmd.OutOrStdout(), cmd.InOrStdin(), lc.get(filepath.Dir(fn)), ho)
 			}
 
-			r, err := cmd.Flags().GetBool("recursively")
-
+			jobs, err := cmd.Flags().GetInt("jobs")
 			if err != nil {
 				panic(err)
 			}
+			keepMtime, err := cmd.Flags().GetBool("keep-mtime")
+			if err != nil {
+				panic(err)
+			}
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				panic(err)
+			}
+			fix, err := cmd.Flags().GetBool("fix")
+			if err != nil {
+				panic(err)
+			}
+			if staged, _ := cmd.Flags().GetBool("staged"); fix && !staged {
+				return usageErrorf("fix flag can be used only with staged flag")
+			}
+			rp, err := newReporter(cmd, "sethead")
+			if err != nil {
+				return err
+			}
 
-			if r {
-				rinput := make([]string, 0, 0)
-				for _, inputPath := range input {
-					ii, err := os.Stat(inputPath)
-					if err != nil {
-						cmd.Println(err)
-					} else {
-
-						rinput = append(rinput, inputPath)
-						if ii.IsDir() {
-							err := filepath.Walk(inputPath, func(p string, fi os.FileInfo, err error) error {
-								if fi.IsDir() {
-									rinput = append(rinput, p)
-								}
-								return nil
-							})
-							if err != nil {
-								cmd.Println(err)
-							}
-						}
-					}
+			lc := newLicenseCache(license, LIsNotSet, config)
+			targets, errs, err := resolveTargets(cmd, lc)
+			if err != nil {
+				return err
+			}
+			for _, err := range errs {
+				cmd.Println(err)
+				rp.fault(err)
+			}
+			if !force && !fix {
+				for _, err := range skipDirtyTargets(targets) {
+					cmd.Println(err)
+					rp.fault(err)
 				}
+			}
 
-				input = rinput
+			j, err := NewJournal("sethead")
+			if err != nil {
+				return fmt.Errorf("cannot create journal: %w", err)
 			}
-			for _, inputPath := range input {
-				err := SetHeaderLicense(inputPath, license, author, cmd.OutOrStdout(), LIsNotSet, config)
+			opt := &tools.WriteOption{KeepModTime: keepMtime}
+			var reports []*FileReport
+			if fix {
+				reports, err = fixStagedTargets(targets, ho, opt, j)
 				if err != nil {
-					cmd.Println(err)
+					j.Close()
+					return err
 				}
+			} else {
+				reports = runHeadJobs(targets, jobs, ho, opt, j)
+			}
+			for _, r := range reports {
+				rp.report(r)
 			}
+			err = rp.finish()
+			if cerr := j.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+			return rp.err()
 		},
 	}
 
-	headCmd.Flags().BoolP("directory", "d", true, "This flag shows whether input is directory or not (default true).")
-	headCmd.Flags().BoolP("recursively", "r", false, "This flag decide whether add license to subdirectory recursively or not. default is false")
-	headCmd.Flags().BoolP("file", "f", false, "If this flag is true, input paths are assumed files.")
+	headCmd.Flags().Bool("force", false, "If this flag is true, files that have uncommitted changes in git work tree are also modified.")
+	headCmd.Flags().Bool("fix", false, "If this flag is true with staged flag, license header of staged content is fixed and fixed files are staged. Partially staged files are not modified.")
+	headCmd.Flags().String("filename", "", "file name of source code read from stdin. It is used to detect LICENSE file of its directory.")
+	headCmd.Flags().Bool("keep-mtime", false, "If this flag is true, modification time of rewritten files is kept.")
+	addTargetFlags(headCmd, false)
 
 	return headCmd
 }
 
 //SetHeaderLicense is add license header to files that do not have license header and change files' license header if the files already have license header.
-func SetHeaderLicense(inputPath string, l *tools.License, author string, messageW io.Writer, LIsNotSet bool, config *Config) error {
-
-	license := l
-	ii, err := os.Stat(inputPath)
-	if err != nil {
-		return err
-	}
-	if LIsNotSet {
-		if ii.IsDir() {
-			license = tools.GetDirLicense(inputPath)
-		} else {
-			license = tools.GetDirLicense(filepath.Dir(inputPath))
-		}
+func SetHeaderLicense(inputPath string, l *tools.License, ho *tools.HeaderOptions, messageW io.Writer, LIsNotSet bool, config *Config) error {
+	lc := newLicenseCache(l, LIsNotSet, config)
+	targets, errs := listHeadTargets([]string{inputPath}, false, lc)
+	if len(errs) > 0 {
+		return errs[0]
 	}
 
-	if ii.IsDir() {
-		sfis, err := ioutil.ReadDir(inputPath)
-
-		if err != nil {
-			return err
-		}
-
-		for _, file := range sfis {
-			if !file.IsDir() && filepath.Ext(file.Name()) == ".go" {
-				fp := filepath.Join(inputPath, file.Name())
-				err := SetFileHeader(fp, file, license, author)
-				if err != nil {
-					fmt.Fprintln(messageW, err)
-				} else {
-					fmt.Fprintln(messageW, "added license header to ", fp, ".")
-				}
-			}
-		}
-	} else {
-		err := SetFileHeader(inputPath, ii, license, author)
-		if err != nil {
-			return err
+	rp := newTextReporter(messageW, "sethead")
+	for _, t := range targets {
+		if t.skip != "" {
+			rp.report(newFileReport(t.path, ActionSkipped, nil).skip(t.skip))
+			continue
 		}
-		fmt.Fprintln(messageW, "added license header to ", inputPath, ".")
+		r, _ := setTargetHeader(&t, ho, nil, nil)
+		rp.report(r)
 	}
 
-	return err
+	return nil
+}
+
+//SetFileHeader set file header to specified license. The file is rewritten atomically according to opt, and its original content is recorded to j.
+//It returns report of the file, which has error if it occurred.
+func SetFileHeader(fp string, fi os.FileInfo, l *tools.License, ho *tools.HeaderOptions, opt *tools.WriteOption, j *Journal) (*FileReport, error) {
+	t := fileTarget(fp, fi, l)
+	return setTargetHeader(&t, ho, opt, j)
 }
 
-//SetFileHeader set file header to specified license.
-func SetFileHeader(fp string, fi os.FileInfo, l *tools.License, author string) error {
-	f, err := os.Open(fp)
+//setTargetHeader sets license header of t like SetFileHeader. Content of t is read from its file system.
+func setTargetHeader(t *headTarget, ho *tools.HeaderOptions, opt *tools.WriteOption, j *Journal) (*FileReport, error) {
+	fp, l := t.path, t.license
+	src, err := t.read()
 	if err != nil {
-		return err
+		return newFileReport(fp, ActionFailed, nil).fail(err), err
+	}
+	r := newFileReport(fp, ActionUpdated, src)
+	fho := ho.ForFile(fp)
+	if headerUpToDate(src, l, fho) {
+		r.setNew(l, src)
+		r.Action = ActionUnchanged
+		return r, nil
 	}
 
-	tmp, err := os.Create(fp + ".tmp")
+	var buf bytes.Buffer
+	err = writeFileHeader(&buf, src, l, fho)
 	if err != nil {
-		return err
+		return r.fail(err), err
 	}
-	tmpw := bufio.NewWriter(tmp)
-
-	sc := bufio.NewScanner(f)
-
-	loop := true
-
-	text := ""
-	s := true
-	for loop {
-		s = sc.Scan()
-		if s {
-			text = sc.Text()
-			ttext := strings.TrimSpace(text)
-			if ttext != "" && ttext != "//" {
-				loop = false
-			}
-		} else {
-			loop = s
-		}
+	r.setNew(l, buf.Bytes())
+	if bytes.Equal(src, buf.Bytes()) {
+		r.Action = ActionUnchanged
+		return r, nil
 	}
-	if strings.HasPrefix(text, "//") {
-		//file begin with comment line
-		ttext := strings.TrimPrefix(text, "//")
-		ttext = strings.TrimSpace(ttext)
-		if strings.HasPrefix(ttext, "Copyright") {
-			//detect Copyright Comment block
-			detecting := true
-			for detecting {
-				s := sc.Scan()
-				if s {
-					text = sc.Text()
-					if !strings.HasPrefix(text, "//") {
-						detecting = false
-					}
-				} else {
-					text = ""
-					detecting = false
-				}
-			}
-			l.WriteLicenseHeader(tmpw, author)
-			if text != "" {
-				for sc.Scan() {
-					tmpw.WriteString(sc.Text())
-				}
-			}
-		} else {
-			l.WriteLicenseHeader(tmpw, author)
-			tmpw.WriteString(text)
-			for sc.Scan() {
-				tmpw.WriteString(sc.Text())
-			}
-		}
-	} else if strings.HasPrefix(text, "/*") {
-		var commentStack strings.Builder
-		if strings.Contains(text, "*/") {
-			ttext := strings.TrimPrefix(text, "/*")
-			ttext = strings.TrimSpace(ttext)
-			if strings.HasPrefix(ttext, "Copyright") {
-				l.WriteLicenseHeader(tmpw, author)
-				e := strings.Index(text, "*/")
-				tmpw.WriteString(text[e+2:])
-				for sc.Scan() {
-					tmpw.WriteString(sc.Text())
-				}
-			}
-		} else {
-			commentStack.WriteString(text)
-			//detecting comment block
-			detecting := true
-			for detecting {
-				if sc.Scan() {
-					commentStack.WriteString(sc.Text())
-					if strings.Contains(sc.Text(), "*/") {
-						detecting = false
-					}
-				} else {
-					detecting = false
-				}
-			}
-			comment := commentStack.String()
-			tcomment := strings.TrimPrefix(comment, "/*")
-			tcomment = strings.TrimSpace(tcomment)
-			if strings.HasPrefix(tcomment, "Copyright") {
-				l.WriteLicenseHeader(tmpw, author)
-				for sc.Scan() {
-					tmpw.WriteString(sc.Text())
-				}
-			} else {
-				l.WriteLicenseHeader(tmpw, author)
-				tmpw.WriteString(comment)
-				for sc.Scan() {
-					tmpw.WriteString(sc.Text())
-				}
-			}
-		}
 
-	} else {
-		l.WriteLicenseHeader(tmpw, author)
-		tmpw.WriteString(text)
-		for sc.Scan() {
-			tmpw.WriteString(sc.Text())
-		}
-	}
-	tmpw.Flush()
-	f.Close()
-	tmp.Close()
-	err = os.Rename(fp+".tmp", fp)
+	err = j.WriteFile(fp, buf.Bytes(), opt)
 	if err != nil {
-		return err
+		return r.fail(err), err
 	}
+	return r, nil
+}
+
+//FilterHeader reads source code from r and writes it to w with license header of l and copyright line of ho. If the source code already has license header, the header is replaced.
+func FilterHeader(w io.Writer, r io.Reader, l *tools.License, ho *tools.HeaderOptions) error {
+	return header.Apply(w, r, &header.Options{
+		License:         l,
+		Author:          ho.Author,
+		Email:           ho.Email,
+		Year:            ho.Year,
+		CopyrightFormat: ho.CopyrightFormat,
+		Template:        ho.Template,
+		Project:         ho.Project,
+		Module:          ho.Module,
+		File:            ho.File,
+		Width:           ho.Width,
+		Style:           ho.Style,
+	})
+}
+
+//headerUpToDate reports whether src already has license header of l that check accepts. Such header is kept even if it is written differently from liquid, like "©" and "(c)".
+func headerUpToDate(src []byte, l *tools.License, ho *tools.HeaderOptions) bool {
+	status, _ := tools.CheckHeader(src, l, ho)
+	return status == tools.HeaderCorrect
+}
 
-	//fmt.Fprintln(messageW, "added license header to ", fp, ".")
-	return err
+//writeFileHeader writes src to w with license header of l. If src already has license header, the header is replaced.
+func writeFileHeader(w io.Writer, src []byte, l *tools.License, ho *tools.HeaderOptions) error {
+	return FilterHeader(w, bytes.NewReader(src), l, ho)
 }
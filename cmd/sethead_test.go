@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+func TestSetFileHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-sethead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := tools.GetOSSLicense("mit")
+	var header bytes.Buffer
+	l.WriteLicenseHeader(&header, &tools.HeaderOptions{Author: "author", Year: 2019})
+	for _, name := range []string{"a.go", "b.go"} {
+		src, err := ioutil.ReadFile(filepath.Join("testdata", "sethead", name+".back"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fp := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(fp, src, 0600); err != nil {
+			t.Fatal(err)
+		}
+		fi, _ := os.Stat(fp)
+
+		for i := 0; i < 2; i++ {
+			if _, err := SetFileHeader(fp, fi, l, &tools.HeaderOptions{Author: "author", Year: 2019}, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		g, _ := ioutil.ReadFile(fp)
+		got := string(g)
+		if strings.Count(got, "Copyright (c)") != 1 || !strings.HasPrefix(got, header.String()+"\n") {
+			t.Errorf("%s: license header is not set correctly", name)
+		}
+		if !strings.Contains(got, "package sethead") {
+			t.Errorf("%s: source code is lost", name)
+		}
+		if name == "a.go" && !strings.Contains(got, "\n\n/*\n//aaaaaa\n//aaaaaa\n*/\n") {
+			t.Errorf("%s: comment that is not license header is lost", name)
+		}
+
+		nfi, _ := os.Stat(fp)
+		if nfi.Mode() != fi.Mode() {
+			t.Errorf("%s: mode changed from %s to %s", name, fi.Mode(), nfi.Mode())
+		}
+	}
+
+	fis, _ := ioutil.ReadDir(dir)
+	if len(fis) != 2 {
+		t.Errorf("temporary files are left: %d files in %s", len(fis), dir)
+	}
+}
+
+func TestSetheadStdin(t *testing.T) {
+	src := "// Copyright (c) 2018 old\n// old license\n\npackage a\n"
+	command := "liquid sethead - -l mit -a author --year 2019"
+	args := strings.Split(command, " ")
+
+	bout := new(bytes.Buffer)
+	lcmd := newRootCmd()
+	lcmd.SetArgs(args[1:])
+	lcmd.SetIn(strings.NewReader(src))
+	lcmd.SetOut(bout)
+	lcmd.SetErr(ioutil.Discard)
+	err := lcmd.Execute()
+	if err != nil {
+		t.Error(err)
+	}
+
+	var header bytes.Buffer
+	tools.GetOSSLicense("mit").WriteLicenseHeader(&header, &tools.HeaderOptions{Author: "author", Year: 2019})
+	//messages of reading config are also written to out.
+	expected := "\n" + header.String() + "\npackage a\n"
+	if got := bout.String(); !strings.HasSuffix(got, expected) || strings.Contains(got, "old") {
+		t.Errorf("license header is not replaced. expected:\n%s\nbut got:\n%s", expected, got)
+	}
+}
+
+func TestSetheadYear(t *testing.T) {
+	run := func(command string) string {
+		bout := new(bytes.Buffer)
+		lcmd := newRootCmd()
+		lcmd.SetArgs(strings.Split(command, " ")[1:])
+		lcmd.SetIn(strings.NewReader("package a\n"))
+		lcmd.SetOut(bout)
+		lcmd.SetErr(ioutil.Discard)
+		if err := lcmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return bout.String()
+	}
+
+	if got := run("liquid sethead - -l mit -a author --year 2000"); !strings.Contains(got, "// Copyright (c) 2000 author\n") {
+		t.Errorf("year flag is not used: %s", got)
+	}
+
+	os.Setenv("SOURCE_DATE_EPOCH", "946684800")
+	defer os.Unsetenv("SOURCE_DATE_EPOCH")
+	if got := run("liquid sethead - -l mit -a author"); !strings.Contains(got, "// Copyright (c) 2000 author\n") {
+		t.Errorf("SOURCE_DATE_EPOCH is not used: %s", got)
+	}
+	if got := run("liquid sethead - -l mit -a author --year 2010"); !strings.Contains(got, "// Copyright (c) 2010 author\n") {
+		t.Errorf("year flag must take precedence over SOURCE_DATE_EPOCH: %s", got)
+	}
+}
+
+func TestSetFileHeaderKeepsCorrect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-sethead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := tools.GetOSSLicense("mit")
+	var header bytes.Buffer
+	l.WriteLicenseHeader(&header, &tools.HeaderOptions{Author: "me", Year: 2020})
+	src := strings.Replace(header.String(), "Copyright (c)", "Copyright ©", 1) + "\npackage a\n"
+	fp := filepath.Join(dir, "a.go")
+	if err := ioutil.WriteFile(fp, []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fi, _ := os.Stat(fp)
+
+	r, err := SetFileHeader(fp, fi, l, &tools.HeaderOptions{Author: "me", Year: 2020}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Action != ActionUnchanged {
+		t.Errorf("header that check accepts must be kept, but action is %s", r.Action)
+	}
+	if got, _ := ioutil.ReadFile(fp); string(got) != src {
+		t.Errorf("file is rewritten:\n%s", got)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+//fixStagedTargets sets license header of targets in git index and stages fixed content. Working tree files are also updated and recorded to j.
+//Files that have unstaged changes are not modified, because their working tree content differs from staged content.
+func fixStagedTargets(targets []headTarget, ho *tools.HeaderOptions, opt *tools.WriteOption, j *Journal) ([]*FileReport, error) {
+	g := newGitStatus()
+	//partial has files that have unstaged changes for each work tree.
+	partial := make(map[string]map[string]bool)
+
+	reports := make([]*FileReport, len(targets))
+	for i, t := range targets {
+		if t.skip != "" {
+			reports[i] = newFileReport(t.path, ActionSkipped, nil).skip(t.skip)
+			continue
+		}
+		ap, err := filepath.Abs(t.path)
+		if err != nil {
+			reports[i] = newFileReport(t.path, ActionFailed, nil).fail(err)
+			continue
+		}
+		//paths output by git do not contain symbolic links.
+		if dir, err := filepath.EvalSymlinks(filepath.Dir(ap)); err == nil {
+			ap = filepath.Join(dir, filepath.Base(ap))
+		}
+		top, err := gitTopLevelOf(g, ap)
+		if err != nil {
+			reports[i] = newFileReport(t.path, ActionFailed, nil).fail(err)
+			continue
+		}
+		p, ok := partial[top]
+		if !ok {
+			unstaged, err := gitUnstagedFiles(top)
+			if err != nil {
+				return nil, err
+			}
+			p = make(map[string]bool)
+			for _, u := range unstaged {
+				p[u] = true
+			}
+			partial[top] = p
+		}
+		if p[ap] {
+			reports[i] = newFileReport(t.path, ActionSkipped, nil).skip("it is partially staged. stage or stash unstaged changes and retry")
+			continue
+		}
+		reports[i] = fixStagedFile(top, ap, t.license, ho, opt, j)
+	}
+
+	return reports, nil
+}
+
+//fixStagedFile sets license header of staged content of the file fp.
+func fixStagedFile(top, fp string, l *tools.License, ho *tools.HeaderOptions, opt *tools.WriteOption, j *Journal) *FileReport {
+	rel, err := filepath.Rel(top, fp)
+	if err != nil {
+		return newFileReport(fp, ActionFailed, nil).fail(err)
+	}
+	rel = filepath.ToSlash(rel)
+
+	mode, src, err := gitReadIndex(top, rel)
+	if err != nil {
+		return newFileReport(fp, ActionFailed, nil).fail(err)
+	}
+	r := newFileReport(fp, ActionUpdated, src)
+	fho := ho.ForFile(fp)
+	if headerUpToDate(src, l, fho) {
+		r.setNew(l, src)
+		r.Action = ActionUnchanged
+		return r
+	}
+
+	var buf bytes.Buffer
+	err = writeFileHeader(&buf, src, l, fho)
+	if err != nil {
+		return r.fail(err)
+	}
+	r.setNew(l, buf.Bytes())
+	if bytes.Equal(buf.Bytes(), src) {
+		r.Action = ActionUnchanged
+		return r
+	}
+
+	err = j.WriteFile(fp, buf.Bytes(), opt)
+	if err == nil {
+		err = gitStage(top, rel, mode, buf.Bytes())
+	}
+	if err != nil {
+		return r.fail(err)
+	}
+	return r
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixStaged(t *testing.T) {
+	dir := newTestRepo(t)
+	defer os.RemoveAll(dir)
+	commitFiles(t, dir, map[string]string{"README.md": "test\n"})
+
+	partial := filepath.Join(dir, "partial.go")
+	staged := filepath.Join(dir, "staged.go")
+	ioutil.WriteFile(partial, []byte("package a\n"), 0644)
+	ioutil.WriteFile(staged, []byte("package a\n"), 0644)
+	runGit(dir, "add", "partial.go", "staged.go")
+	ioutil.WriteFile(partial, []byte("package a\n\nvar unstaged int\n"), 0644)
+
+	bout := new(bytes.Buffer)
+	lcmd := newRootCmd()
+	lcmd.SetArgs([]string{"sethead", "--staged", "--fix", "-l", "mit", "-a", "author", "--year", "2019", dir})
+	lcmd.SetOut(bout)
+	lcmd.SetErr(ioutil.Discard)
+	if err := lcmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := bout.String(); !strings.Contains(got, "skipped "+partial+": it is partially staged") {
+		t.Errorf("partially staged file must be reported as skipped with the reason: %s", got)
+	}
+	if _, b, _ := gitReadIndex(dir, "partial.go"); string(b) != "package a\n" {
+		t.Errorf("staged content of partially staged file must not be modified: %q", b)
+	}
+	if b, _ := ioutil.ReadFile(partial); string(b) != "package a\n\nvar unstaged int\n" {
+		t.Errorf("partially staged file must not be modified: %q", b)
+	}
+
+	_, b, _ := gitReadIndex(dir, "staged.go")
+	if !strings.HasPrefix(string(b), "// Copyright (c) 2019 author\n") {
+		t.Errorf("license header is not added to staged content: %q", b)
+	}
+	if wb, _ := ioutil.ReadFile(staged); !bytes.Equal(wb, b) {
+		t.Errorf("working tree file must have the same content as staged: %q", wb)
+	}
+}
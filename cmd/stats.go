@@ -0,0 +1,297 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+// newStatsCmd represents the stats command
+func newStatsCmd() *cobra.Command {
+	statsCmd := &cobra.Command{
+		Use:   "stats [Paths of files or directories]",
+		Short: "print statistics of license headers of .go files in input directory or specified files.",
+		Long: `liquid stats classifies license header of files like liquid check, and prints the number and percentage of files with correct, outdated, foreign and missing header by language, directory and license. Subdirectories are processed by default.
+In addition to common formats, stats supports csv and markdown format for pasting into documents.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				panic(err)
+			}
+			write, ok := statsWriters[format]
+			if !ok {
+				return usageErrorf("stats command does not support format %s", format)
+			}
+
+			config, license, author, LIsNotSet, err := ProcessArg(cmd, args)
+			if err != nil {
+				return err
+			}
+
+			ho, err := getHeaderOptions(cmd, author, config)
+			if err != nil {
+				return err
+			}
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				panic(err)
+			}
+
+			lc := newLicenseCache(license, LIsNotSet, config)
+			targets, errs, err := resolveTargets(cmd, lc)
+			if err != nil {
+				return err
+			}
+			for _, err := range errs {
+				cmd.Println(err)
+			}
+
+			reports := runCheckJobs(targets, jobs, ho)
+			err = write(cmd.OutOrStdout(), newHeaderStats(reports))
+			if err != nil {
+				return err
+			}
+			return failureError(reports, errs)
+		},
+	}
+
+	addTargetFlags(statsCmd, true)
+
+	return statsCmd
+}
+
+//Groups of header statistics.
+const (
+	StatsTotal     = "total"
+	StatsLanguage  = "language"
+	StatsDirectory = "directory"
+	StatsLicense   = "license"
+)
+
+//HeaderStats is the number of files for each class of license header in a group of files.
+type HeaderStats struct {
+	//Group is how files are grouped: total, language, directory or license.
+	Group string `json:"group"`
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	//Correct is the number of files whose license header is correct.
+	Correct int `json:"correct"`
+	//Outdated is the number of files whose license header has wrong license, old year or placeholder holder.
+	Outdated int `json:"outdated"`
+	//Foreign is the number of files whose license header belongs to other copyright holder.
+	Foreign int `json:"foreign"`
+	Missing int `json:"missing"`
+	//Failed is the number of files that cannot be read.
+	Failed int `json:"failed"`
+}
+
+//add counts r.
+func (s *HeaderStats) add(r *FileReport) {
+	s.Files++
+	if r.Action == ActionFailed {
+		s.Failed++
+		return
+	}
+	switch r.status {
+	case tools.HeaderCorrect:
+		s.Correct++
+	case tools.HeaderForeign:
+		s.Foreign++
+	case tools.HeaderMissing:
+		s.Missing++
+	default:
+		s.Outdated++
+	}
+}
+
+//counts returns the numbers of s in the order of statsColumns.
+func (s *HeaderStats) counts() []int {
+	return []int{s.Correct, s.Outdated, s.Foreign, s.Missing, s.Failed}
+}
+
+//statsColumns are names of classes of license header.
+var statsColumns = []string{"correct", "outdated", "foreign", "missing", "failed"}
+
+//extensionsOf returns file extensions of language lang. lang is case insensitive.
+func extensionsOf(lang string) []string {
+	for _, l := range tools.Languages {
+		if strings.EqualFold(l.Name, lang) {
+			return l.Extensions
+		}
+	}
+	return nil
+}
+
+func languageOf(fp string) string {
+	if l := tools.LanguageOf(fp); l != nil {
+		return l.Name
+	}
+	return "other"
+}
+
+//newHeaderStats aggregates reports of check into statistics of total, each language, each directory and each license. Statistics of the same group are sorted by name.
+func newHeaderStats(reports []*FileReport) []*HeaderStats {
+	total := &HeaderStats{Group: StatsTotal, Name: "all"}
+	groups := make(map[string]*HeaderStats)
+	var stats []*HeaderStats
+
+	get := func(group, name string) *HeaderStats {
+		k := group + "\x00" + name
+		s, ok := groups[k]
+		if !ok {
+			s = &HeaderStats{Group: group, Name: name}
+			groups[k] = s
+			stats = append(stats, s)
+		}
+		return s
+	}
+
+	for _, r := range reports {
+		if r.Action == ActionSkipped {
+			continue
+		}
+		total.add(r)
+		get(StatsLanguage, languageOf(r.Path)).add(r)
+		get(StatsDirectory, relPath(filepath.Dir(r.Path))).add(r)
+		l := "none"
+		if r.Action == ActionFailed {
+			l = "unknown"
+		} else if r.status != tools.HeaderMissing {
+			l = r.OldLicense
+		}
+		get(StatsLicense, l).add(r)
+	}
+
+	order := map[string]int{StatsLanguage: 0, StatsDirectory: 1, StatsLicense: 2}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Group != stats[j].Group {
+			return order[stats[i].Group] < order[stats[j].Group]
+		}
+		return stats[i].Name < stats[j].Name
+	})
+
+	return append([]*HeaderStats{total}, stats...)
+}
+
+//statsWriters are writers of header statistics for each format.
+var statsWriters = map[string]func(io.Writer, []*HeaderStats) error{
+	"text":     writeStatsText,
+	"json":     writeStatsJSON,
+	"ndjson":   writeStatsNDJSON,
+	"csv":      writeStatsCSV,
+	"markdown": writeStatsMarkdown,
+}
+
+//countText formats n with its percentage of total like "3 (50%)".
+func countText(n, total int) string {
+	return fmt.Sprintf("%d (%d%%)", n, percent(n, total))
+}
+
+func writeStatsText(w io.Writer, stats []*HeaderStats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\r\n", "GROUP", "NAME", "FILES", strings.ToUpper(strings.Join(statsColumns, "\t")))
+	for _, s := range stats {
+		cols := []string{s.Group, s.Name, strconv.Itoa(s.Files)}
+		for _, n := range s.counts() {
+			cols = append(cols, countText(n, s.Files))
+		}
+		fmt.Fprintf(tw, "%s\r\n", strings.Join(cols, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeStatsJSON(w io.Writer, stats []*HeaderStats) error {
+	b, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
+
+func writeStatsNDJSON(w io.Writer, stats []*HeaderStats) error {
+	for _, s := range stats {
+		b, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeStatsCSV(w io.Writer, stats []*HeaderStats) error {
+	cw := csv.NewWriter(w)
+	head := []string{"group", "name", "files"}
+	for _, c := range statsColumns {
+		head = append(head, c, c+"_percent")
+	}
+	cw.Write(head)
+	for _, s := range stats {
+		rec := []string{s.Group, s.Name, strconv.Itoa(s.Files)}
+		for _, n := range s.counts() {
+			rec = append(rec, strconv.Itoa(n), strconv.Itoa(percent(n, s.Files)))
+		}
+		cw.Write(rec)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+//writeStatsMarkdown writes a table for each group.
+func writeStatsMarkdown(w io.Writer, stats []*HeaderStats) error {
+	group := ""
+	for _, s := range stats {
+		if s.Group != group {
+			if group != "" {
+				fmt.Fprint(w, "\n")
+			}
+			group = s.Group
+			fmt.Fprintf(w, "### %s\n\n", strings.ToUpper(group[:1])+group[1:])
+			fmt.Fprintf(w, "| %s | files | %s |\n", group, strings.Join(statsColumns, " | "))
+			fmt.Fprintf(w, "|---|---:|%s\n", strings.Repeat("---:|", len(statsColumns)))
+		}
+		cols := []string{strings.Replace(s.Name, "|", "\\|", -1), strconv.Itoa(s.Files)}
+		for _, n := range s.counts() {
+			cols = append(cols, countText(n, s.Files))
+		}
+		_, err := fmt.Fprintf(w, "| %s |\n", strings.Join(cols, " | "))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
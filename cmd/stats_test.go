@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatsCSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0755)
+	ioutil.WriteFile(filepath.Join(dir, "missing.go"), []byte("package a\n"), 0644)
+	ioutil.WriteFile(filepath.Join(sub, "foreign.go"), []byte("// Copyright (c) 2019 someone\n// other license\n\npackage a\n"), 0644)
+
+	bout := new(bytes.Buffer)
+	lcmd := newRootCmd()
+	lcmd.SetArgs([]string{"stats", dir, "-l", "mit", "-a", "author", "--format", "csv"})
+	lcmd.SetOut(bout)
+	lcmd.SetErr(ioutil.Discard)
+	if err := lcmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	//messages of reading config are written before statistics.
+	out := bout.Bytes()
+	if i := bytes.Index(out, []byte("group,")); i > 0 {
+		out = out[i:]
+	}
+	t.Log(string(out))
+	recs, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make(map[string][]string)
+	for _, r := range recs[1:] {
+		rows[r[0]+" "+r[1]] = r
+	}
+	if r := rows["total all"]; r == nil || r[2] != "2" || r[7] != "1" || r[9] != "1" {
+		t.Errorf("wrong total: %v", r)
+	}
+	if r := rows["directory "+filepath.ToSlash(sub)]; r == nil || r[7] != "1" || r[8] != "100" {
+		t.Errorf("subdirectory must be processed recursively: %v", rows)
+	}
+	if r := rows["license none"]; r == nil || r[9] != "1" {
+		t.Errorf("wrong license statistics: %v", r)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/suquiya/liquid/tools"
+)
+
+// newStripCmd represents the strip command
+func newStripCmd() *cobra.Command {
+	stripCmd := &cobra.Command{
+		Use:   "strip [Paths of files or directories]",
+		Short: "remove license header from .go files in input directory or specified files.",
+		Long: `liquid strip removes license header recognized by liquid from .go files. Package documentation, build constraints and other comments are kept.
+If license flag is specified, only headers of the license are removed. If author flag is specified, only headers whose copyright holders include the author are removed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := getStripFilter(cmd)
+			if err != nil {
+				return err
+			}
+			jobs, err := cmd.Flags().GetInt("jobs")
+			if err != nil {
+				panic(err)
+			}
+			keepMtime, err := cmd.Flags().GetBool("keep-mtime")
+			if err != nil {
+				panic(err)
+			}
+			force, err := cmd.Flags().GetBool("force")
+			if err != nil {
+				panic(err)
+			}
+			rp, err := newReporter(cmd, "strip")
+			if err != nil {
+				return err
+			}
+
+			lc := newLicenseCache(f.license, false, NewConfig())
+			targets, errs, err := resolveTargets(cmd, lc)
+			if err != nil {
+				return err
+			}
+			for _, err := range errs {
+				cmd.Println(err)
+				rp.fault(err)
+			}
+			if !force {
+				for _, err := range skipDirtyTargets(targets) {
+					cmd.Println(err)
+					rp.fault(err)
+				}
+			}
+
+			j, err := NewJournal("strip")
+			if err != nil {
+				return fmt.Errorf("cannot create journal: %w", err)
+			}
+			opt := &tools.WriteOption{KeepModTime: keepMtime}
+			for _, r := range runStripJobs(targets, jobs, f, opt, j) {
+				rp.report(r)
+			}
+			err = rp.finish()
+			if cerr := j.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return err
+			}
+			return rp.err()
+		},
+	}
+
+	stripCmd.Flags().Bool("force", false, "If this flag is true, files that have uncommitted changes in git work tree are also modified.")
+	stripCmd.Flags().Bool("keep-mtime", false, "If this flag is true, modification time of rewritten files is kept.")
+	addTargetFlags(stripCmd, false)
+
+	return stripCmd
+}
+
+//stripFilter selects license headers removed by strip. If license or holder is empty, headers of any license or holder are selected.
+type stripFilter struct {
+	license *tools.License
+	holder  string
+}
+
+//getStripFilter returns stripFilter from license and author flags of cmd. Flags that are not specified by user do not limit headers.
+func getStripFilter(cmd *cobra.Command) (*stripFilter, error) {
+	f := &stripFilter{}
+	if cmd.Flags().Changed("license") {
+		name, err := cmd.Flags().GetString("license")
+		if err != nil {
+			panic(err)
+		}
+		f.license, err = tools.LookupOSSLicense(name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cmd.Flags().Changed("author") {
+		holder, err := cmd.Flags().GetString("author")
+		if err != nil {
+			panic(err)
+		}
+		f.holder = normalizeHolder(holder)
+	}
+	return f, nil
+}
+
+//match reports whether license header info is selected by f. If not, the reason is returned.
+func (f *stripFilter) match(info *tools.HeaderInfo) (bool, string) {
+	if f.license != nil && tools.DetectLicense(info.Text) != f.license {
+		return false, "license header is not " + f.license.Name
+	}
+	if f.holder == "" {
+		return true, ""
+	}
+	for _, h := range splitHolders(info.Holder) {
+		if normalizeHolder(h) == f.holder {
+			return true, ""
+		}
+	}
+	return false, "license header belongs to other copyright holder"
+}
+
+//normalizeHolder collapses white spaces of copyright holder h.
+func normalizeHolder(h string) string {
+	return strings.Join(strings.Fields(h), " ")
+}
+
+//stripFileHeader removes license header of t if it is selected by f. The file is rewritten atomically according to opt, and its original content is recorded to j.
+func stripFileHeader(t *headTarget, f *stripFilter, opt *tools.WriteOption, j *Journal) *FileReport {
+	fp := t.path
+	src, err := t.read()
+	if err != nil {
+		return newFileReport(fp, ActionFailed, nil).fail(err)
+	}
+	r := newFileReport(fp, ActionStripped, src)
+	if r.header == nil {
+		return r.skip("no license header")
+	}
+	if ok, reason := f.match(r.header); !ok {
+		return r.skip(reason)
+	}
+
+	_, body := tools.SplitHeader(src)
+	err = j.WriteFile(fp, bytes.TrimLeft(body, "\r\n"), opt)
+	if err != nil {
+		return r.fail(err)
+	}
+	return r
+}
+
+//runStripJobs removes license header of targets with n workers. Files are written according to opt and recorded to j. Reports are returned in the same order as targets.
+func runStripJobs(targets []headTarget, n int, f *stripFilter, opt *tools.WriteOption, j *Journal) []*FileReport {
+	reports := make([]*FileReport, len(targets))
+	parallel(n, len(targets), func(i int) {
+		t := targets[i]
+		if t.skip != "" {
+			reports[i] = newFileReport(t.path, ActionSkipped, nil).skip(t.skip)
+			return
+		}
+		reports[i] = stripFileHeader(&t, f, opt, j)
+	})
+	return reports
+}
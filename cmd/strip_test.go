@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+func TestStrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-strip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	header := func(holder, license string) string {
+		return "// Copyright (c) 2019 " + holder + "\n" + tools.CommentifyString(tools.OSSLicenses[license].Header) + "\n"
+	}
+	body := "// +build linux\n\n// Package a is documentation of package.\npackage a\n"
+	mit := filepath.Join(dir, "mit.go")
+	apache := filepath.Join(dir, "apache.go")
+	ioutil.WriteFile(mit, []byte(header("me", "mit")+body), 0644)
+	ioutil.WriteFile(apache, []byte(header("me and other", "apache")+"\n"+body), 0644)
+
+	run := func(args ...string) string {
+		bout := new(bytes.Buffer)
+		lcmd := newRootCmd()
+		lcmd.SetArgs(append([]string{"strip", "--force", "-f"}, append(args, mit, apache)...))
+		lcmd.SetOut(bout)
+		lcmd.SetErr(ioutil.Discard)
+		if err := lcmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		t.Log(bout.String())
+		return bout.String()
+	}
+
+	out := run("-l", "mit")
+	if !strings.Contains(out, "skipped "+apache+": license header is not ") {
+		t.Errorf("header of other license must be skipped: %s", out)
+	}
+	if b, _ := ioutil.ReadFile(mit); string(b) != body {
+		t.Errorf("header is not stripped or other comments are removed: %q", b)
+	}
+
+	run("-a", "other")
+	if b, _ := ioutil.ReadFile(apache); string(b) != body {
+		t.Errorf("header of holder is not stripped: %q", b)
+	}
+
+	out = run()
+	if !strings.Contains(out, "skipped "+mit+": no license header") {
+		t.Errorf("file without header must be skipped: %s", out)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright © 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// newUndoCmd represents the undo command
+func newUndoCmd() *cobra.Command {
+	undoCmd := &cobra.Command{
+		Use:   "undo [run id]",
+		Short: "restore files modified by a run of liquid.",
+		Long: `liquid undo restores files modified by a run of liquid from the journal recorded in .liquid/journal of the git repository of current directory, or of current directory out of git repository. If run id is not specified, the last run is undone.
+If some of the files were changed after the run, liquid undo restores nothing.`,
+		Args: usageArgs(cobra.MaximumNArgs(1)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ids, err := ListJournals()
+			if err != nil {
+				return err
+			}
+
+			list, err := cmd.Flags().GetBool("list")
+			if err != nil {
+				panic(err)
+			}
+			if list {
+				for _, id := range ids {
+					entries, _ := ReadJournalEntries(id)
+					fmt.Fprintf(cmd.OutOrStdout(), "%s\t%d files\r\n", id, len(entries))
+				}
+				return nil
+			}
+
+			var id string
+			if len(args) > 0 {
+				id = args[0]
+			} else if len(ids) > 0 {
+				id = ids[len(ids)-1]
+			} else {
+				return fmt.Errorf("no run to undo")
+			}
+
+			rp, err := newReporter(cmd, "undo")
+			if err != nil {
+				return err
+			}
+			entries, err := UndoJournal(id)
+			for _, e := range entries {
+				if e.Backup == "" {
+					rp.report(newFileReport(e.Path, ActionRemoved, nil))
+				} else {
+					rp.report(newFileReport(e.Path, ActionRestored, nil))
+				}
+			}
+			if ferr := rp.finish(); ferr != nil && err == nil {
+				err = ferr
+			}
+			if err != nil {
+				return err
+			}
+			cmd.Printf("run %s is undone.\r\n", id)
+			return nil
+		},
+	}
+
+	undoCmd.Flags().Bool("list", false, "list runs that can be undone.")
+
+	return undoCmd
+}
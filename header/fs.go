@@ -0,0 +1,108 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package header
+
+import (
+	"bytes"
+	"io/fs"
+	"path"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+//WriteFS is a file system that files can be written to in addition to reading by fs.FS.
+type WriteFS = tools.WriteFS
+
+//OverlayFS is WriteFS that keeps written files in memory over read only file system.
+type OverlayFS = tools.OverlayFS
+
+//DirFS returns WriteFS of the directory dir in OS file system. Files are written atomically.
+func DirFS(dir string) WriteFS {
+	return tools.DirFS(dir)
+}
+
+//NewOverlayFS creates OverlayFS over base. base can be read only file system such as fstest.MapFS or zip archive.
+func NewOverlayFS(base fs.FS) *OverlayFS {
+	return tools.NewOverlayFS(base)
+}
+
+//Files returns slash separated names of .go files under root of fsys recursively. If some directories cannot be read, the first error is returned with names of readable files.
+func Files(fsys fs.FS, root string) ([]string, error) {
+	names, errs := tools.GoFiles(fsys, root, true)
+	if len(errs) > 0 {
+		return names, errs[0]
+	}
+	return names, nil
+}
+
+//CheckFile returns status of license header of the file name in fsys. See Check.
+func CheckFile(fsys fs.FS, name string, opt *Options) (Status, *Info, error) {
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return Missing, nil, err
+	}
+	return Check(bytes.NewReader(src), opt.forFile(name))
+}
+
+//forFile returns copy of opt whose File is base name of name if it is not specified.
+func (opt *Options) forFile(name string) *Options {
+	if opt == nil || opt.File != "" {
+		return opt
+	}
+	o := *opt
+	o.File = path.Base(name)
+	return &o
+}
+
+//ApplyFile sets license header of the file name in fsys. It reports whether the file is changed. The file is not written if its header is already up to date.
+func ApplyFile(fsys WriteFS, name string, opt *Options) (bool, error) {
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return false, err
+	}
+	var b bytes.Buffer
+	err = Apply(&b, bytes.NewReader(src), opt.forFile(name))
+	if err != nil {
+		return false, err
+	}
+	return writeIfChanged(fsys, name, src, b.Bytes())
+}
+
+//StripFile removes license header of the file name in fsys. It reports whether the file is changed.
+func StripFile(fsys WriteFS, name string) (bool, error) {
+	src, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return false, err
+	}
+	var b bytes.Buffer
+	err = Strip(&b, bytes.NewReader(src))
+	if err != nil {
+		return false, err
+	}
+	return writeIfChanged(fsys, name, src, b.Bytes())
+}
+
+func writeIfChanged(fsys WriteFS, name string, src, data []byte) (bool, error) {
+	if bytes.Equal(src, data) {
+		return false, nil
+	}
+	return true, fsys.WriteFile(name, data)
+}
@@ -0,0 +1,61 @@
+package header
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestOverlayFS(t *testing.T) {
+	base := fstest.MapFS{
+		"a.go":           {Data: []byte("package a\n")},
+		"sub/b.go":       {Data: []byte("// Copyright (c) 2010 someone\n// other license\n\npackage b\n")},
+		"sub/README":     {Data: []byte("readme\n")},
+		"sub/c/c.go":     {Data: []byte("package c\n")},
+		"vendor/LICENSE": {Data: []byte("license\n")},
+	}
+	l, _ := OSSLicense("mit")
+	opt := &Options{License: l, Author: "author"}
+
+	names, err := Files(base, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 3 {
+		t.Fatalf("expected 3 go files, but got %v", names)
+	}
+
+	o := NewOverlayFS(base)
+	for _, name := range names {
+		if _, err := ApplyFile(o, name, opt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range names {
+		status, _, err := CheckFile(o, name, opt)
+		if err != nil || status != Correct {
+			t.Errorf("%s: expected correct, but got %s (%v)", name, status, err)
+		}
+		if status, _, _ := CheckFile(base, name, opt); status == Correct {
+			t.Errorf("%s: base file system must not be modified", name)
+		}
+	}
+	if w := o.Written(); len(w) != 3 {
+		t.Errorf("expected 3 written files, but got %v", w)
+	}
+
+	changed, err := ApplyFile(o, "a.go", opt)
+	if err != nil || changed {
+		t.Errorf("up to date file must not be changed: %v, %v", changed, err)
+	}
+	changed, err = StripFile(o, "a.go")
+	if err != nil || !changed {
+		t.Errorf("header must be stripped: %v, %v", changed, err)
+	}
+	if b, _ := fs.ReadFile(o, "a.go"); string(b) != "package a\n" {
+		t.Errorf("wrong stripped content: %q", b)
+	}
+	if fi, err := fs.Stat(o, "a.go"); err != nil || fi.Name() != "a.go" || fi.Size() != int64(len("package a\n")) || fi.IsDir() {
+		t.Errorf("wrong file info of written file: %v, %v", fi, err)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//Package header applies, checks, strips and detects license headers of go source code.
+//It is the library API of liquid, so that programs such as code generators can stamp license headers without running liquid command.
+package header
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"github.com/suquiya/liquid/tools"
+)
+
+//License is a license whose header is written to source code.
+type License = tools.License
+
+//Info is parsed license header of source code.
+type Info = tools.HeaderInfo
+
+//Status is status of license header of source code.
+type Status = tools.HeaderStatus
+
+//Statuses of license header.
+const (
+	Correct           = tools.HeaderCorrect
+	Missing           = tools.HeaderMissing
+	WrongLicense      = tools.HeaderWrongLicense
+	StaleYear         = tools.HeaderStaleYear
+	PlaceholderHolder = tools.HeaderPlaceholderHolder
+	Foreign           = tools.HeaderForeign
+)
+
+//ErrNoLicense is returned when license is not specified in Options.
+var ErrNoLicense = errors.New("header: license is not specified")
+
+//Options are options of license header written to or expected in source code.
+type Options struct {
+	//License is license of the header.
+	License *License
+	//Author is copyright holder of the header.
+	Author string
+	//Email is email address of copyright holder. It is written only if CopyrightFormat uses it.
+	Email string
+	//CopyrightFormat is text/template of copyright line, in which Year, Holder and Email can be used.
+	//If it is empty, "Copyright (c) {{.Year}} {{.Holder}}" is used. Copyright marks "Copyright", "(c)" and "©" are treated as equivalent by Check.
+	CopyrightFormat string
+	//Template is text/template of the whole header, whose data is HeaderData. Its output is commented in Style.
+	//If it is empty, "{{.Copyright}}\n{{.License}}" is used. It must begin with copyright line so that the header is detected.
+	Template string
+	//Project, Module and File are project name, go module path and file name that can be used in Template.
+	Project string
+	Module  string
+	File    string
+	//Width is maximum width of header lines including comment marks. Header text is reflowed to the width, keeping blank lines and list items. If it is 0, header text is not reflowed.
+	Width int
+	//Style is comment style of the header: LineComment, BlockComment or DocComment. Existing header of any style is replaced by Apply.
+	Style CommentStyle
+	//Year is copyright year of the header. If it is 0, year is taken from SOURCE_DATE_EPOCH environment variable or Clock.
+	Year int
+	//Clock returns current time used for copyright year. If it is nil, time.Now is used.
+	Clock Clock
+}
+
+//CommentStyle is comment style of license header.
+type CommentStyle = tools.CommentType
+
+//Comment styles of license header.
+const (
+	//LineComment writes header as "//" lines.
+	LineComment = tools.Lines
+	//BlockComment writes header as "/* */" block.
+	BlockComment = tools.Wrap
+	//DocComment writes header as "/** */" block.
+	DocComment = tools.DocWrap
+)
+
+//Clock returns current time. It is injected to make headers reproducible.
+type Clock = tools.Clock
+
+//HeaderData is data of header template.
+type HeaderData = tools.HeaderData
+
+func (opt *Options) validate() error {
+	if opt == nil || opt.License == nil {
+		return ErrNoLicense
+	}
+	return nil
+}
+
+//headerOptions returns options of tools for opt.
+func (opt *Options) headerOptions() (*tools.HeaderOptions, error) {
+	year := opt.Year
+	if year <= 0 {
+		var err error
+		year, err = tools.CopyrightYear(opt.Clock)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &tools.HeaderOptions{
+		Author:          opt.Author,
+		Email:           opt.Email,
+		Year:            year,
+		CopyrightFormat: opt.CopyrightFormat,
+		Template:        opt.Template,
+		Project:         opt.Project,
+		Module:          opt.Module,
+		File:            opt.File,
+		Width:           opt.Width,
+		Style:           opt.Style,
+	}, nil
+}
+
+//OSSLicense returns OSS license of name such as "mit" or "apache".
+func OSSLicense(name string) (*License, error) {
+	return tools.LookupOSSLicense(name)
+}
+
+//Apply reads source code from r and writes it to w with license header specified by opt. If the source code already has license header, the header is replaced.
+//If the existing header has the same copyright holder, its first copyright year is kept as YearRange of HeaderData.
+func Apply(w io.Writer, r io.Reader, opt *Options) error {
+	if err := opt.validate(); err != nil {
+		return err
+	}
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	ho, err := opt.headerOptions()
+	if err != nil {
+		return err
+	}
+	ho = ho.Inherit(tools.ParseHeader(src), opt.License)
+	_, body := tools.SplitHeader(src)
+
+	bw := bufio.NewWriter(w)
+	err = opt.License.WriteLicenseHeader(bw, ho)
+	if err != nil {
+		return err
+	}
+	body = bytes.TrimLeft(body, "\r\n")
+	if len(body) > 0 {
+		bw.WriteString("\n")
+		bw.Write(body)
+	}
+
+	return bw.Flush()
+}
+
+//Check reads source code from r and returns status of its license header compared with the header specified by opt. Parsed header is also returned, and it is nil if the source code has no license header.
+func Check(r io.Reader, opt *Options) (Status, *Info, error) {
+	if err := opt.validate(); err != nil {
+		return Missing, nil, err
+	}
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Missing, nil, err
+	}
+	ho, err := opt.headerOptions()
+	if err != nil {
+		return Missing, nil, err
+	}
+	status, info := tools.CheckHeader(src, opt.License, ho)
+	return status, info, nil
+}
+
+//Strip reads source code from r and writes it to w without license header.
+func Strip(w io.Writer, r io.Reader) error {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	_, body := tools.SplitHeader(src)
+	_, err = w.Write(bytes.TrimLeft(body, "\r\n"))
+	return err
+}
+
+//Detect reads source code from r and returns its parsed license header and license detected from the header. If the source code has no license header, both are nil.
+//License is nil also if the header is not one of OSS licenses.
+func Detect(r io.Reader) (*Info, *License, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	info := tools.ParseHeader(src)
+	if info == nil {
+		return nil, nil, nil
+	}
+	return info, tools.DetectLicense(info.Text), nil
+}
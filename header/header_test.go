@@ -0,0 +1,92 @@
+package header
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyCheckStrip(t *testing.T) {
+	l, err := OSSLicense("mit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := &Options{License: l, Author: "author", Clock: func() time.Time {
+		return time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	}}
+	src := "// Copyright (c) 2010 someone\n// other license\n\npackage a\n"
+
+	status, _, err := Check(strings.NewReader(src), opt)
+	if err != nil || status != Foreign {
+		t.Errorf("expected foreign, but got %s (%v)", status, err)
+	}
+
+	var applied bytes.Buffer
+	err = Apply(&applied, strings.NewReader(src), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(applied.String())
+	status, info, err := Check(bytes.NewReader(applied.Bytes()), opt)
+	if err != nil || status != Correct {
+		t.Errorf("expected correct after Apply, but got %s (%v)", status, err)
+	}
+	if info == nil || info.Holder != "author" || info.Years != "2019" {
+		t.Errorf("wrong header is applied: %+v", info)
+	}
+	if _, dl, _ := Detect(bytes.NewReader(applied.Bytes())); dl != l {
+		t.Errorf("expected %s is detected, but got %v", l.Name, dl)
+	}
+
+	var stripped bytes.Buffer
+	err = Strip(&stripped, bytes.NewReader(applied.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stripped.String() != "package a\n" {
+		t.Errorf("header is not stripped: %q", stripped.String())
+	}
+
+	if err := Apply(&applied, strings.NewReader(src), &Options{}); err != ErrNoLicense {
+		t.Errorf("expected ErrNoLicense, but got %v", err)
+	}
+}
+
+func TestCommentStyle(t *testing.T) {
+	l, err := OSSLicense("mit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := &Options{License: l, Author: "author", Year: 2019}
+	src := "// Copyright (c) 2018 author\n// old text\n\npackage a\n"
+
+	for _, style := range []CommentStyle{BlockComment, DocComment, LineComment} {
+		opt.Style = style
+		var converted bytes.Buffer
+		err = Apply(&converted, strings.NewReader(src), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := converted.String()
+		switch style {
+		case BlockComment:
+			if !strings.HasPrefix(out, "/*\n * Copyright (c) 2019 author\n *\n * Permission") || !strings.Contains(out, "\n */\n\npackage a\n") {
+				t.Errorf("header is not converted to block comment: %q", out)
+			}
+		case DocComment:
+			if !strings.HasPrefix(out, "/**\n * Copyright (c) 2019 author\n *\n * Permission") {
+				t.Errorf("header is not converted to doc comment: %q", out)
+			}
+		case LineComment:
+			if !strings.HasPrefix(out, "// Copyright (c) 2019 author\n// ") || strings.Contains(out, "*/") {
+				t.Errorf("header is not converted to line comment: %q", out)
+			}
+		}
+		if status, _, err := Check(bytes.NewReader(converted.Bytes()), opt); err != nil || status != Correct {
+			t.Errorf("expected correct after conversion to %s, but got %s (%v)", style, status, err)
+		}
+
+		src = out
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tools
+
+import (
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+//WriteOption is option of WriteFileAtomic.
+type WriteOption struct {
+	//KeepModTime keeps modification time of the file that is overwritten.
+	KeepModTime bool
+}
+
+//WriteFileAtomic writes data to the file fp atomically.
+//data is written to a temporary file in the same directory, synced and renamed to fp, so fp is never left half written.
+//If fp already exists, its mode and ownership are copied to the new file. Otherwise the file is created with mode 0666 masked by umask, like os.WriteFile.
+//The temporary file is removed if an error occurs. Temporary files being written are removed by RemoveTempFiles.
+func WriteFileAtomic(fp string, data []byte, opt *WriteOption) (err error) {
+	if opt == nil {
+		opt = &WriteOption{}
+	}
+
+	fi, err := os.Stat(fp)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if os.IsNotExist(err) {
+		fi = nil
+	}
+
+	dir, base := filepath.Split(fp)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := createTemp(dir, "."+base+".liquid-")
+	if err != nil {
+		return err
+	}
+	tp := tmp.Name()
+	untrack := trackTempFile(tp)
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tp)
+		}
+		untrack()
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+
+	//new file keeps mode of the temporary file, which is decided by umask.
+	if fi != nil {
+		if err = chown(tmp, fi); err != nil {
+			return err
+		}
+		if err = tmp.Chmod(fi.Mode() & (os.ModePerm | os.ModeSetuid | os.ModeSetgid | os.ModeSticky)); err != nil {
+			return err
+		}
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if opt.KeepModTime && fi != nil {
+		if err = os.Chtimes(tp, time.Now(), fi.ModTime()); err != nil {
+			return err
+		}
+	}
+
+	if err = os.Rename(tp, fp); err != nil {
+		return err
+	}
+
+	syncDir(dir)
+	return nil
+}
+
+//syncDir syncs directory entry after rename. Some platforms cannot open directories, so errors are ignored.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	d.Sync()
+	d.Close()
+}
+
+//createTemp creates new temporary file in dir whose name begins with prefix. The file is created with mode 0666 masked by umask.
+func createTemp(dir, prefix string) (*os.File, error) {
+	for i := 0; ; i++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 36))
+		f, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && i < 10000 {
+			continue
+		}
+		return f, err
+	}
+}
+
+var (
+	tempFiles   = make(map[string]bool)
+	tempFilesMu sync.Mutex
+)
+
+//trackTempFile registers p as temporary file which is removed by RemoveTempFiles. Returned function unregisters p.
+func trackTempFile(p string) func() {
+	tempFilesMu.Lock()
+	tempFiles[p] = true
+	tempFilesMu.Unlock()
+
+	return func() {
+		tempFilesMu.Lock()
+		delete(tempFiles, p)
+		tempFilesMu.Unlock()
+	}
+}
+
+//RemoveTempFiles removes temporary files that WriteFileAtomic is writing. Programs call it when they are interrupted, so that no temporary file is left.
+func RemoveTempFiles() {
+	tempFilesMu.Lock()
+	defer tempFilesMu.Unlock()
+	for tp := range tempFiles {
+		os.Remove(tp)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tools
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+//Clock returns current time. It is injected to make output reproducible.
+type Clock func() time.Time
+
+//SourceDateEpochEnv is environment variable that specifies time of reproducible builds as seconds since unix epoch.
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+//Now returns time specified by SOURCE_DATE_EPOCH environment variable if it is set, and time of clock otherwise. If clock is nil, time.Now is used.
+func Now(clock Clock) (time.Time, error) {
+	if v := os.Getenv(SourceDateEpochEnv); v != "" {
+		sec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s %q: it must be seconds since unix epoch", SourceDateEpochEnv, v)
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	return clock(), nil
+}
+
+//CopyrightYear returns year of copyright written now. See Now.
+func CopyrightYear(clock Clock) (int, error) {
+	t, err := Now(clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Year(), nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tools
+
+import (
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+//DefaultCopyrightFormat is default template of copyright line of license header.
+const DefaultCopyrightFormat = "Copyright (c) {{.Year}} {{.Holder}}"
+
+//HeaderOptions are options of license header written by liquid.
+type HeaderOptions struct {
+	//Author is copyright holder.
+	Author string
+	//Email is email address of copyright holder. It is written only if CopyrightFormat uses it.
+	Email string
+	//Year is copyright year.
+	Year int
+	//CopyrightFormat is text/template of copyright line. Year, Holder and Email can be used in it, like "Copyright © {{.Year}} The {{.Holder}} Authors. All rights reserved.".
+	//If it is empty, DefaultCopyrightFormat is used.
+	CopyrightFormat string
+	//Template is header template. If it is empty, DefaultHeaderTemplate is used. It must begin with copyright line so that the header is detected.
+	Template string
+	//Project is name of the project.
+	Project string
+	//Module and File are go module path and base name of the file that the header is written to. They are set by ForFile.
+	Module string
+	File   string
+	//FirstYear is the first copyright year of existing header. It is set by Inherit.
+	FirstYear int
+	//Width is maximum width of header lines including comment marks. Header text is reflowed to the width. If it is 0, header text is not reflowed.
+	Width int
+	//Style is comment type that header is written in. Styles maps file extensions such as ".go" to comment types, and ForFile sets Style of the file from it.
+	Style  CommentType
+	Styles map[string]CommentType
+}
+
+//copyrightData is data of template of copyright line.
+type copyrightData struct {
+	Year   string
+	Holder string
+	Email  string
+}
+
+//ValidateCopyrightFormat reports error if format is not valid template of copyright line.
+func ValidateCopyrightFormat(format string) error {
+	_, err := copyrightLine(format, copyrightData{"2019", "author", "author@example.com"})
+	return err
+}
+
+//CopyrightLine returns copyright line of license header written with opt.
+func (opt *HeaderOptions) CopyrightLine() (string, error) {
+	return copyrightLine(opt.CopyrightFormat, copyrightData{strconv.Itoa(opt.Year), opt.Author, opt.Email})
+}
+
+//expected returns parsed license header of l written with opt. If the header cannot be written, it returns nil.
+func (opt *HeaderOptions) expected(l *License) *HeaderInfo {
+	var sb strings.Builder
+	if err := l.WriteLicenseHeader(&sb, opt); err != nil {
+		return nil
+	}
+	return ParseHeader([]byte(sb.String()))
+}
+
+//Inherit returns copy of opt that keeps the first copyright year of existing header info, if info has the same copyright holder as opt.
+func (opt *HeaderOptions) Inherit(info *HeaderInfo, l *License) *HeaderOptions {
+	o := *opt
+	if info == nil {
+		return &o
+	}
+	if exp := opt.expected(l); exp != nil && sameText(exp.Holder, info.Holder) {
+		o.FirstYear = firstYear(info.Years)
+	}
+	return &o
+}
+
+func copyrightLine(format string, data copyrightData) (string, error) {
+	if format == "" {
+		format = DefaultCopyrightFormat
+	}
+	t, err := template.New("copyright").Option("missingkey=error").Parse(format)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	err = t.Execute(&sb, data)
+	if err != nil {
+		return "", err
+	}
+	return normalizeSpace(sb.String()), nil
+}
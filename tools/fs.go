@@ -0,0 +1,160 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tools
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//WriteFS is a file system that files can be written to in addition to reading by fs.FS.
+type WriteFS interface {
+	fs.FS
+	//WriteFile writes data to the file name. If the file already exists, its content is replaced.
+	WriteFile(name string, data []byte) error
+}
+
+//DirFS returns WriteFS of the directory dir in OS file system. Files are written atomically with WriteFileAtomic, and existing files keep their permission.
+func DirFS(dir string) WriteFS {
+	return &dirFS{os.DirFS(dir), dir}
+}
+
+type dirFS struct {
+	fs.FS
+	dir string
+}
+
+func (d *dirFS) WriteFile(name string, data []byte) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	return WriteFileAtomic(filepath.Join(d.dir, filepath.FromSlash(name)), data, nil)
+}
+
+//OverlayFS is WriteFS that keeps written files in memory over read only base file system. Base file system is never modified, so OverlayFS is used for dry run, archives and tests with fstest.MapFS.
+//Files that exist only in OverlayFS are not listed by reading their directory.
+type OverlayFS struct {
+	base    fs.FS
+	mu      sync.Mutex
+	written map[string]*memFile
+}
+
+//NewOverlayFS creates OverlayFS over base.
+func NewOverlayFS(base fs.FS) *OverlayFS {
+	return &OverlayFS{base: base, written: make(map[string]*memFile)}
+}
+
+//Open opens the file name. Written content is returned if the file has been written.
+func (o *OverlayFS) Open(name string) (fs.File, error) {
+	o.mu.Lock()
+	f, ok := o.written[name]
+	o.mu.Unlock()
+	if ok {
+		return &openMemFile{f, bytes.NewReader(f.data)}, nil
+	}
+	return o.base.Open(name)
+}
+
+//WriteFile keeps data as content of the file name.
+func (o *OverlayFS) WriteFile(name string, data []byte) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.written[name] = &memFile{name, append([]byte(nil), data...), time.Now()}
+	return nil
+}
+
+//Written returns sorted names of files written to o.
+func (o *OverlayFS) Written() []string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	names := make([]string, 0, len(o.written))
+	for name := range o.written {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//memFile is a file written to OverlayFS. It is also fs.FileInfo of itself.
+type memFile struct {
+	name    string
+	data    []byte
+	modTime time.Time
+}
+
+func (f *memFile) Name() string       { return path.Base(f.name) }
+func (f *memFile) Size() int64        { return int64(len(f.data)) }
+func (f *memFile) Mode() fs.FileMode  { return 0644 }
+func (f *memFile) ModTime() time.Time { return f.modTime }
+func (f *memFile) IsDir() bool        { return false }
+func (f *memFile) Sys() interface{}   { return nil }
+
+//openMemFile is memFile opened for reading. Written content is never changed, so it can be read while the file is written again.
+type openMemFile struct {
+	*memFile
+	r *bytes.Reader
+}
+
+func (f *openMemFile) Stat() (fs.FileInfo, error) { return f.memFile, nil }
+func (f *openMemFile) Read(b []byte) (int, error) { return f.r.Read(b) }
+func (f *openMemFile) Close() error               { return nil }
+
+//GoFiles returns slash separated names of .go files in dir of fsys. If recursive is true, .go files in subdirectories are also returned.
+//Subdirectories ignored by IsIgnoredDir are not listed. Errors in reading directories do not stop listing, and they are returned with the names.
+func GoFiles(fsys fs.FS, dir string, recursive bool) ([]string, []error) {
+	var names []string
+	var errs []error
+	err := fs.WalkDir(fsys, dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if d.IsDir() {
+			if name != dir && (!recursive || IsIgnoredDir(d.Name())) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(name) == ".go" {
+			names = append(names, name)
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return names, errs
+}
+
+//IsIgnoredDir reports whether directory name is not walked for source files of the project: vendor, testdata and hidden directories.
+func IsIgnoredDir(name string) bool {
+	return name == "vendor" || name == "testdata" || (strings.HasPrefix(name, ".") && name != "." && name != "..")
+}
@@ -0,0 +1,343 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tools
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	lineCommentMark  = []byte("//")
+	wrapCommentStart = []byte("/*")
+	wrapCommentEnd   = []byte("*/")
+)
+
+//SplitHeader splits src into its license header and the rest of source code.
+//License header is the first comment block of src that begins with copyright mark such as "Copyright", "(c)" or "©". Blank lines and empty line comments before it are included in header, and build constraints and package documentation after it are not.
+//If src has no license header, header is nil and body is src without leading blank lines.
+func SplitHeader(src []byte) (header, body []byte) {
+	pos := 0
+	for pos < len(src) {
+		line, next := nextLine(src, pos)
+		t := bytes.TrimSpace(line)
+		if len(t) != 0 && !bytes.Equal(t, lineCommentMark) {
+			break
+		}
+		pos = next
+	}
+
+	rest := src[pos:]
+	switch {
+	case bytes.HasPrefix(rest, lineCommentMark):
+		if !isCopyright(bytes.TrimPrefix(rest, lineCommentMark)) {
+			return nil, rest
+		}
+		end := pos
+		for end < len(src) {
+			line, next := nextLine(src, end)
+			if !bytes.HasPrefix(line, lineCommentMark) || isHeaderEnd(line) {
+				break
+			}
+			end = next
+		}
+		return src[:end], src[end:]
+	case bytes.HasPrefix(rest, wrapCommentStart):
+		if !isCopyright(bytes.TrimLeft(bytes.TrimPrefix(rest, wrapCommentStart), "* \t\r\n")) {
+			return nil, rest
+		}
+		e := bytes.Index(rest, wrapCommentEnd)
+		if e < 0 {
+			return src, nil
+		}
+		end := pos + e + len(wrapCommentEnd)
+		line, next := nextLine(src, end)
+		if len(bytes.TrimSpace(line)) == 0 {
+			end = next
+		}
+		return src[:end], src[end:]
+	}
+
+	return nil, rest
+}
+
+//headerEnds are prefixes of line comments that are not part of line comment header even if they are written just after it, such as build constraints and package documentation.
+var headerEnds = [][]byte{[]byte("//go:"), []byte("// +build"), []byte("//+build"), []byte("// Package ")}
+
+func isHeaderEnd(line []byte) bool {
+	for _, e := range headerEnds {
+		if bytes.HasPrefix(line, e) {
+			return true
+		}
+	}
+	return false
+}
+
+//copyrightMarks are marks that begin copyright line. They are treated as equivalent.
+var copyrightMarks = [][]byte{[]byte("copyright"), []byte("(c)"), []byte("©")}
+
+func isCopyright(b []byte) bool {
+	t := bytes.ToLower(bytes.TrimSpace(b))
+	for _, m := range copyrightMarks {
+		if bytes.HasPrefix(t, m) {
+			return true
+		}
+	}
+	return false
+}
+
+//nextLine returns the line of src beginning at pos without its new line code, and the position of the next line.
+func nextLine(src []byte, pos int) ([]byte, int) {
+	i := bytes.IndexByte(src[pos:], '\n')
+	if i < 0 {
+		return bytes.TrimSuffix(src[pos:], []byte{'\r'}), len(src)
+	}
+	return bytes.TrimSuffix(src[pos:pos+i], []byte{'\r'}), pos + i + 1
+}
+
+//HeaderStatus is status of license header of a source file compared with expected license and author.
+type HeaderStatus int
+
+const (
+	//HeaderCorrect means the file has expected license header.
+	HeaderCorrect HeaderStatus = iota
+	//HeaderMissing means the file has no license header.
+	HeaderMissing
+	//HeaderWrongLicense means license header of the file is not expected license.
+	HeaderWrongLicense
+	//HeaderStaleYear means copyright year of the file is older than current year.
+	HeaderStaleYear
+	//HeaderPlaceholderHolder means copyright holder of the file is placeholder like "COPYRIGHT HOLDER".
+	HeaderPlaceholderHolder
+	//HeaderForeign means the file has license header of other copyright holder.
+	HeaderForeign
+)
+
+var headerStatusNames = []string{"correct", "missing", "wrong-license", "stale-year", "placeholder-holder", "foreign"}
+
+func (s HeaderStatus) String() string {
+	if int(s) < len(headerStatusNames) {
+		return headerStatusNames[s]
+	}
+	return "unknown"
+}
+
+//Description returns human readable description of s.
+func (s HeaderStatus) Description() string {
+	switch s {
+	case HeaderCorrect:
+		return "license header is correct"
+	case HeaderMissing:
+		return "license header is missing"
+	case HeaderWrongLicense:
+		return "license header is not expected license"
+	case HeaderStaleYear:
+		return "copyright year is not current year"
+	case HeaderPlaceholderHolder:
+		return "copyright holder is placeholder"
+	case HeaderForeign:
+		return "license header belongs to other copyright holder"
+	}
+	return "unknown status"
+}
+
+//HeaderInfo is information of license header parsed from source code.
+type HeaderInfo struct {
+	//Years is copyright years written in header, like "2019" or "2018-2019".
+	Years string
+	//Holder is copyright holder written in header.
+	Holder string
+	//Text is uncommented text of header without copyright line.
+	Text string
+	//StartLine and EndLine are line numbers (1-based) of the first and the last line of header.
+	StartLine int
+	EndLine   int
+}
+
+//copyrightPattern matches copyright line. "Copyright", "(c)", "©" and their combinations are equivalent.
+var copyrightPattern = regexp.MustCompile(`^(?:(?i:copyright)|\([cC]\)|©)(?:(?i:copyright)|\([cC]\)|©|:|\s)*((?:[0-9]{4})(?:\s*[-,]\s*[0-9]{4})*)?[,.]?\s*(.*)$`)
+
+//placeholderHolders are copyright holders that are not filled by real name.
+var placeholderHolders = []string{"", "COPYRIGHT HOLDER", "COPYRIGHT HOLDERS", "AUTHOR", "AUTHORS", "<copyright holders>", "[fullname]", "[name of copyright owner]", "<name of author>", "{name of copyright owner}"}
+
+//ParseHeader parses license header of src. If src has no license header, it returns nil.
+func ParseHeader(src []byte) *HeaderInfo {
+	header, _ := SplitHeader(src)
+	if header == nil {
+		return nil
+	}
+
+	info := &HeaderInfo{}
+	lines := uncomment(header)
+	start := -1
+	var text []string
+	for i, l := range lines {
+		if start < 0 {
+			if l == "" {
+				continue
+			}
+			start = i
+			m := copyrightPattern.FindStringSubmatch(l)
+			if m != nil {
+				info.Years = strings.Join(strings.Fields(m[1]), "")
+				info.Holder = strings.TrimSpace(m[2])
+			}
+			continue
+		}
+		text = append(text, l)
+	}
+	info.StartLine = start + 1
+	info.EndLine = len(lines)
+	for info.EndLine > info.StartLine && lines[info.EndLine-1] == "" {
+		info.EndLine--
+	}
+	info.Text = strings.TrimSpace(strings.Join(text, "\n"))
+
+	return info
+}
+
+//uncomment returns lines of comment block b without comment marks.
+func uncomment(b []byte) []string {
+	var lines []string
+	for pos := 0; pos < len(b); {
+		line, next := nextLine(b, pos)
+		pos = next
+		l := strings.TrimSpace(string(line))
+		if strings.HasPrefix(l, "//") {
+			l = l[2:]
+		} else {
+			l = strings.TrimPrefix(l, "/*")
+			l = strings.TrimSuffix(l, "*/")
+			if strings.HasPrefix(l, "*") && !strings.HasPrefix(l, "*/") {
+				l = l[1:]
+			}
+		}
+		lines = append(lines, strings.TrimSpace(l))
+	}
+	return lines
+}
+
+//CheckHeader checks license header of src with expected license l, and copyright holder and year of opt.
+//Copyright holder and header text are compared as they are written with templates of opt, and copyright marks are treated as equivalent.
+//It returns status of the header and parsed header information, which is nil if src has no license header.
+func CheckHeader(src []byte, l *License, opt *HeaderOptions) (HeaderStatus, *HeaderInfo) {
+	info := ParseHeader(src)
+	if info == nil {
+		return HeaderMissing, nil
+	}
+
+	for _, p := range placeholderHolders {
+		if info.Holder == p {
+			return HeaderPlaceholderHolder, info
+		}
+	}
+	holder, text := opt.Author, l.Header
+	o := *opt
+	o.FirstYear = firstYear(info.Years)
+	if exp := o.expected(l); exp != nil {
+		holder, text = exp.Holder, exp.Text
+	}
+	if opt.Author != "" && !sameText(info.Holder, holder) {
+		return HeaderForeign, info
+	}
+	if !sameText(info.Text, text) {
+		return HeaderWrongLicense, info
+	}
+	if lastYear(info.Years) < opt.Year {
+		return HeaderStaleYear, info
+	}
+
+	return HeaderCorrect, info
+}
+
+//DetectLicense returns license in OSSLicenses whose header is text. If no license matches, it returns nil.
+func DetectLicense(text string) *License {
+	if normalizeSpace(text) == "" {
+		return nil
+	}
+	for _, ol := range OSSLicenses {
+		if sameText(ol.Header, text) {
+			return ol
+		}
+	}
+	return nil
+}
+
+//sameText reports whether texts of license header a and b are the same except for wrapping and "*/" escaped in block comment.
+func sameText(a, b string) bool {
+	return normalizeSpace(escapeBlockComment(a)) == normalizeSpace(escapeBlockComment(b))
+}
+
+//normalizeSpace collapses white spaces of s so that texts wrapped differently can be compared.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+//BumpCopyrightYear returns src whose copyright years of license header are extended to year, like "2018" to "2018-2019". Other parts of src are kept.
+//If src has no license header or its copyright years already include year, src is returned as it is.
+func BumpCopyrightYear(src []byte, year int) []byte {
+	header, body := SplitHeader(src)
+	for pos := 0; pos < len(header); {
+		line, next := nextLine(header, pos)
+		if l := uncomment(line); len(l) > 0 && l[0] != "" {
+			m := copyrightPattern.FindStringSubmatch(l[0])
+			if m == nil || m[1] == "" || lastYear(m[1]) >= year {
+				return src
+			}
+			years := strconv.Itoa(year)
+			if first := firstYear(m[1]); first < year {
+				years = strconv.Itoa(first) + "-" + years
+			}
+			var b bytes.Buffer
+			b.Write(header[:pos])
+			b.Write(bytes.Replace(line, []byte(m[1]), []byte(years), 1))
+			b.Write(header[pos+len(line):])
+			b.Write(body)
+			return b.Bytes()
+		}
+		pos = next
+	}
+	return src
+}
+
+//firstYear returns the earliest year in years. If years has no year, it returns 0.
+func firstYear(years string) int {
+	y := 0
+	for _, f := range strings.FieldsFunc(years, func(r rune) bool { return r == '-' || r == ',' }) {
+		if n, err := strconv.Atoi(strings.TrimSpace(f)); err == nil && (y == 0 || n < y) {
+			y = n
+		}
+	}
+	return y
+}
+
+//lastYear returns the latest year in years. If years has no year, it returns 0.
+func lastYear(years string) int {
+	y := 0
+	for _, f := range strings.FieldsFunc(years, func(r rune) bool { return r == '-' || r == ',' }) {
+		if n, err := strconv.Atoi(strings.TrimSpace(f)); err == nil && n > y {
+			y = n
+		}
+	}
+	return y
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckHeader(t *testing.T) {
+	l := &License{Name: "test", Header: "Licensed under the test license.\nSee LICENSE."}
+	cases := []struct {
+		src    string
+		status HeaderStatus
+	}{
+		{"package a\n", HeaderMissing},
+		{"// Package a is a.\npackage a\n", HeaderMissing},
+		{"// Copyright (c) 2019 author\n//\n// Licensed under the test license.\n// See LICENSE.\n\npackage a\n", HeaderCorrect},
+		{"// Copyright © 2018-2019 author\n// Licensed under the test\n// license. See LICENSE.\n\npackage a\n", HeaderCorrect},
+		{"/*\nCopyright 2019 author\n\nLicensed under the test license.\nSee LICENSE.\n*/\n\npackage a\n", HeaderCorrect},
+		{"// Copyright (c) 2019 author\n// Licensed under other license.\n\npackage a\n", HeaderWrongLicense},
+		{"// Copyright (c) 2018 author\n// Licensed under the test license.\n// See LICENSE.\n\npackage a\n", HeaderStaleYear},
+		{"// Copyright (c) 2019 COPYRIGHT HOLDER\n// Licensed under the test license.\n// See LICENSE.\n\npackage a\n", HeaderPlaceholderHolder},
+		{"// Copyright (c) 2019 someone\n// Licensed under the test license.\n// See LICENSE.\n\npackage a\n", HeaderForeign},
+		{"// (C) 2019 author\n// Licensed under the test license.\n// See LICENSE.\n\npackage a\n", HeaderCorrect},
+		{"// © Copyright 2019 author\n// Licensed under the test license.\n// See LICENSE.\n\npackage a\n", HeaderCorrect},
+	}
+
+	for i, c := range cases {
+		status, _ := CheckHeader([]byte(c.src), l, &HeaderOptions{Author: "author", Year: 2019})
+		if status != c.status {
+			t.Errorf("case %d: expected %s, but got %s", i, c.status, status)
+		}
+	}
+}
+
+func TestCopyrightFormat(t *testing.T) {
+	l := &License{Name: "test", Header: "Licensed under the test license."}
+	opt := &HeaderOptions{Author: "Go", Email: "go@example.com", Year: 2019, CopyrightFormat: "Copyright © {{.Year}} The {{.Holder}} Authors <{{.Email}}>. All rights reserved."}
+
+	var b strings.Builder
+	if err := l.WriteLicenseHeader(&b, opt); err != nil {
+		t.Fatal(err)
+	}
+	t.Log(b.String())
+	if !strings.HasPrefix(b.String(), "// Copyright © 2019 The Go Authors <go@example.com>. All rights reserved.\n") {
+		t.Errorf("copyright line is not formatted: %s", b.String())
+	}
+
+	cases := []struct {
+		src    string
+		status HeaderStatus
+	}{
+		{b.String() + "\npackage a\n", HeaderCorrect},
+		{"// Copyright (c) 2019 The Go Authors <go@example.com>. All rights reserved.\n// Licensed under the test license.\n\npackage a\n", HeaderCorrect},
+		{"// Copyright 2018-2019 The Go Authors <go@example.com>. All rights reserved.\n// Licensed under the test license.\n\npackage a\n", HeaderCorrect},
+		{"// Copyright (c) 2019 Go\n// Licensed under the test license.\n\npackage a\n", HeaderForeign},
+	}
+	for i, c := range cases {
+		status, _ := CheckHeader([]byte(c.src), l, opt)
+		if status != c.status {
+			t.Errorf("case %d: expected %s, but got %s", i, c.status, status)
+		}
+	}
+
+	if err := ValidateCopyrightFormat("Copyright {{.Year"); err == nil {
+		t.Error("invalid format must be error")
+	}
+	if err := ValidateCopyrightFormat("Copyright {{.Owner}}"); err == nil {
+		t.Error("unknown field must be error")
+	}
+}
+
+func TestBumpCopyrightYear(t *testing.T) {
+	tests := []struct {
+		src, expected string
+	}{
+		{"// Copyright © 2018 suquiya\n\npackage a\n", "// Copyright © 2018-2019 suquiya\n\npackage a\n"},
+		{"/*\nCopyright (c) 2015 - 2017 a, b\n*/\n\npackage a\n", "/*\nCopyright (c) 2015-2019 a, b\n*/\n\npackage a\n"},
+		{"// Copyright 2019 suquiya\n\npackage a\n", "// Copyright 2019 suquiya\n\npackage a\n"},
+		{"package a\n", "package a\n"},
+	}
+	for _, tt := range tests {
+		if got := string(BumpCopyrightYear([]byte(tt.src), 2019)); got != tt.expected {
+			t.Errorf("expected %q, but got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestCommentifyBlockEnd(t *testing.T) {
+	l := &License{Name: "test", Header: "Files matching */*.go are licensed\nunder the test license. */"}
+	for _, ct := range []CommentType{Wrap, DocWrap} {
+		var b strings.Builder
+		if err := l.WriteLicenseHeader(&b, &HeaderOptions{Author: "author", Year: 2019, Style: ct}); err != nil {
+			t.Fatal(err)
+		}
+		header := b.String()
+		if i := strings.Index(header, "*/"); i != strings.LastIndex(header, "*/") {
+			t.Errorf("%s: block comment is ended in license text: %q", ct, header)
+		}
+		status, _ := CheckHeader([]byte(header+"\n\npackage a\n"), l, &HeaderOptions{Author: "author", Year: 2019})
+		if status != HeaderCorrect {
+			t.Errorf("%s: expected %s, but got %s", ct, HeaderCorrect, status)
+		}
+	}
+}
@@ -36,7 +36,7 @@ var (
 	startLineComment []byte
 	startWrapComment []byte
 	endWrapComment   []byte
-	crlf []byte
+	crlf             []byte
 )
 
 func init() {
@@ -49,7 +49,7 @@ func init() {
 	startLineComment = arr(slash, slash)
 	startWrapComment = arr(slash, asterisk)
 	endWrapComment = arr(asterisk, slash)
-	crlf = arr(cr,lf)
+	crlf = arr(cr, lf)
 }
 
 //Lexer is lexer for sethead. Lexer tokenizes comment part.
@@ -135,20 +135,20 @@ func (l *Lexer) GetLinesBlock() *CommentBlock {
 		ct.r = append(ct.r, line...)
 		ct.content = append(ct.content, bytes.TrimSpace(line)...)
 		detect := true
-		for detect{
+		for detect {
 			line, CanR = l.readBytes(lf)
-			if CanR{
-				if bytes.HasPrefix(line, startLineComment){
+			if CanR {
+				if bytes.HasPrefix(line, startLineComment) {
 					ct.r = append(ct.r, line...)
 					ct.content = append(ct.content, bytes.TrimSpace(line)...)
 					//detect = true
-				}else{
+				} else {
 					switch {
 					case bytes.HasPrefix(line, startWrapComment):
 						l.next = CommentWrapBlockToken
 					case bytes.Equal(line, crlf) || line[0] == lf:
 						l.next = BlankLineToken
-					case bytes.HasPrefix("package"):
+					case bytes.HasPrefix(line, []byte("package")):
 						l.next = PackageDeclarationToken
 					default:
 						l.next = NormalStringToken
@@ -156,9 +156,9 @@ func (l *Lexer) GetLinesBlock() *CommentBlock {
 					l.nextBytes = line
 					detect = false
 				}
-			}else{
+			} else {
 				l.next = EOFToken
-				detect = canR
+				detect = CanR
 			}
 		}
 		return ct
@@ -169,27 +169,27 @@ func (l *Lexer) GetLinesBlock() *CommentBlock {
 }
 
 //GetWrapCommentBlock return wrap comments
-func GetWrapCommentBlock() *CommentBlock{
-	cb := &CommentBlock{Wrap, []byte{},[]byte{}}
-	cb.r = append(cb.r, startWrapComment)
+func (l *Lexer) GetWrapCommentBlock() *CommentBlock {
+	cb := &CommentBlock{Wrap, []byte{}, []byte{}}
+	cb.r = append(cb.r, startWrapComment...)
 
 	seek := true
-	for seek{
+	for seek {
 		block, CanR := l.readBytes(slash)
-		if CanR{
+		if CanR {
 			blen := len(block)
-			if blen > 1{
-				cb.r = append(cb.r,block)
-				if block[blen-2] == asterisk{
+			if blen > 1 {
+				cb.r = append(cb.r, block...)
+				if block[blen-2] == asterisk {
 					seek = false
 				}
 			}
-		}else{
+		} else {
 			seek = CanR
 			l.next = EOFToken
 		}
 	}
-	
+	return cb
 }
 
 func getBytesArray(b byte, n int) []byte {
@@ -211,7 +211,7 @@ func (l *Lexer) readBytes(b byte) ([]byte, bool) {
 		return nil, false
 	}
 	barr, err := l.s.ReadBytes(b)
-	if len(barr) <1　&& err != nil{
+	if len(barr) < 1 && err != nil {
 		return nil, false
 	}
 	l.err = err
@@ -224,8 +224,8 @@ func (l *Lexer) readByte() (byte, bool) {
 	}
 	b, err := l.s.ReadByte()
 	l.err = err
-	if err != nil && b == 0{
-		return b,false
+	if err != nil && b == 0 {
+		return b, false
 	}
 	return b, true
 }
@@ -324,7 +324,10 @@ type CommentBlock struct {
 
 //Type method returns c's TokenType
 func (c *CommentBlock) Type() TokenType {
-	return CommentBlockToken
+	if c.ct == Lines {
+		return CommentLineBlockToken
+	}
+	return CommentWrapBlockToken
 }
 
 //CommentMethod returns c's CommentType
@@ -352,4 +355,6 @@ const (
 	Lines CommentType = iota
 	//Wrap means type of "/*" ~ "*/"
 	Wrap
+	//DocWrap means type of "/**" ~ "*/"
+	DocWrap
 )
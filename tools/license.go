@@ -27,9 +27,8 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
-	"text/template"
-	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/spf13/cobra/cobra/cmd"
@@ -43,6 +42,28 @@ type License struct {
 	Name   string
 	Text   string
 	Header string
+	//SPDX is SPDX license identifier. It is empty for custom licenses.
+	SPDX string
+}
+
+//spdxIDs are SPDX license identifiers of OSSLicenses.
+var spdxIDs = map[string]string{
+	"mit":     "MIT",
+	"apache":  "Apache-2.0",
+	"freebsd": "BSD-2-Clause",
+	"bsd":     "BSD-3-Clause",
+	"gpl2":    "GPL-2.0-or-later",
+	"gpl3":    "GPL-3.0-or-later",
+	"lgpl":    "LGPL-3.0-or-later",
+	"agpl":    "AGPL-3.0-or-later",
+}
+
+//URL returns URL of license text on SPDX license list. It is empty for licenses without SPDX identifier.
+func (l *License) URL() string {
+	if l.SPDX == "" {
+		return ""
+	}
+	return "https://spdx.org/licenses/" + l.SPDX + ".html"
 }
 
 func convertCLToLL(cl *cmd.License) *License {
@@ -57,87 +78,85 @@ func init() {
 	OSSLicenses = make(map[string]*License)
 	for key, cl := range cmd.Licenses {
 		l := convertCLToLL(&cl)
+		l.SPDX = spdxIDs[key]
 		OSSLicenses[key] = l
 		OSSLicenses[l.Name] = l
 	}
 }
 
-//CreateCustomLicense create License struct from file
+//CreateCustomLicense create License struct from file. If one of the files does not exist, the other is used as both header and text.
 func CreateCustomLicense(headPath, textPath string) (*License, error) {
 	h := headPath
 	t := textPath
 	e, err := IsExistFilePath(h)
 	e2, err2 := IsExistFilePath(t)
 
-	if e {
-		if e2 {
-			return &License{Name: "custom", Header: readFile(h), Text: readFile(t)}, nil
-		}
-		fmt.Println(err2)
+	if !e && !e2 {
+		return nil, &IOError{Op: "read custom license", Path: h, Err: fmt.Errorf("%s\r\n%s", err.Error(), err2.Error())}
+	}
+	if !e {
 		h = t
-		hStr := readFile(h)
-		return &License{Name: "custom", Header: hStr, Text: hStr}, nil
 	}
-	if e2 {
+	if !e2 {
 		t = h
-		tStr := readFile(t)
-		return &License{Name: "custom", Header: tStr, Text: tStr}, nil
 	}
 
-	return nil, fmt.Errorf("%s\r\n%s", err.Error(), err2.Error())
-
+	hStr, err := readFile(h)
+	if err != nil {
+		return nil, err
+	}
+	tStr := hStr
+	if t != h {
+		tStr, err = readFile(t)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &License{Name: "custom", Header: hStr, Text: tStr}, nil
 }
 
-//GetOSSLicense get an OSSLicense struct from license name
+//GetOSSLicense get an OSSLicense struct from license name. If the license is not found, it returns mit license.
 func GetOSSLicense(licenseName string) *License {
-	li, exist := OSSLicenses[licenseName]
-	if !exist {
-		err := fmt.Errorf("OSSLicenses not hit")
-		fmt.Println(err)
-		fmt.Println("liquid automatically choose mit")
-		licenseName = "mit"
-		li, _ = OSSLicenses[licenseName]
-		//fmt.Println(OSSLicenses)
+	li, err := LookupOSSLicense(licenseName)
+	if err != nil {
+		li = OSSLicenses["mit"]
 	}
 	return li
 }
 
-func readFile(path string) string {
-	b, err := ioutil.ReadFile(path)
-	if err != nil {
-		panic(err)
+//LookupOSSLicense get an OSSLicense struct from license name. If the license is not found, it returns UnknownLicenseError.
+func LookupOSSLicense(licenseName string) (*License, error) {
+	li, exist := OSSLicenses[licenseName]
+	if !exist {
+		return nil, &UnknownLicenseError{licenseName}
 	}
-	return string(b)
+	return li, nil
 }
 
-//WriteLicenseHeader write license header to w
-func (l *License) WriteLicenseHeader(w io.Writer, author string) {
-	ct := getNowCopyrightText(author)
-	data := make(map[string]interface{})
-	data["copyright"] = ct
-	data["licenseHeader"] = l.Header
-
-	template := `{{comment .copyright}}
-{{comment .licenseHeader}}
-`
-	err := execTemplate(template, data, w)
+func readFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
 	if err != nil {
-		panic(err)
+		return "", &IOError{Op: "read", Path: path, Err: err}
 	}
-
+	return string(b), nil
 }
 
-func execTemplate(tmpl string, data interface{}, w io.Writer) error {
-	t, err := template.New("").Funcs(template.FuncMap{"comment": CommentifyString}).Parse(tmpl)
-
+//WriteLicenseHeader write license header of opt to w. The header is rendered with header template of opt and commented in comment style of opt.
+func (l *License) WriteLicenseHeader(w io.Writer, opt *HeaderOptions) error {
+	text, err := opt.HeaderText(l)
 	if err != nil {
 		return err
 	}
-
-	return t.Execute(w, data)
-
+	if opt.Width > 0 {
+		text = Reflow(text, opt.Width-commentPrefixLen(opt.Style))
+	}
+	_, err = io.WriteString(w, Commentify(text, opt.Style)+"\n")
+	return err
 }
 
+//lineCommentPrefix is prefix of lines commented by CommentifyString.
+const lineCommentPrefix = "// "
+
 //CommentifyString commentify string inspired by cobra's commentifyString
 func CommentifyString(input string) string {
 	nlcode := "\n"
@@ -147,7 +166,7 @@ func CommentifyString(input string) string {
 	lines := strings.Split(inputNLd, "\n")
 	var sb strings.Builder
 	sb.Grow(len(input) + len(lines)*len("\n"))
-	c := "// "
+	c := lineCommentPrefix
 	for _, l := range lines {
 		if strings.HasPrefix(l, c) {
 			sb.WriteString(l)
@@ -164,16 +183,6 @@ func CommentifyString(input string) string {
 	return strings.TrimSuffix(sb.String(), nlcode)
 }
 
-func getNowCopyrightText(author string) string {
-	var sb strings.Builder
-	sb.Grow(19 + len(author))
-	sb.WriteString("Copyright (c) ")
-	sb.WriteString(time.Now().Format("2006"))
-	sb.WriteString(" ")
-	sb.WriteString(author)
-	return sb.String()
-}
-
 //GetDirLicense get license based on license file in dir
 func GetDirLicense(dir string) *License {
 	lc := findAndGetLicenseContent(dir)
@@ -185,9 +194,24 @@ func GetDirLicense(dir string) *License {
 	l = nil
 	lcStr := string(lc)
 	lcStr = strings.TrimSpace(lcStr)
-	for _, ol := range OSSLicenses {
+	//licenses are compared in fixed order so that the result does not depend on order of map.
+	keys := make([]string, 0, len(OSSLicenses))
+	for k := range OSSLicenses {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		ol := OSSLicenses[k]
 		t := strings.TrimSpace(ol.Text)
 		h := strings.TrimSpace(ol.Header)
+		//license that has no text like "None" matches any file.
+		if t == "" || h == "" {
+			continue
+		}
+		//text after copyright line is compared, because copyright line differs in each project.
+		if i := strings.Index(t, copyrightPlaceholder); i >= 0 {
+			t = strings.TrimSpace(t[i+len(copyrightPlaceholder):])
+		}
 		if strings.HasSuffix(lcStr, t) || strings.HasPrefix(lcStr, h) {
 			l = ol
 			break
@@ -206,27 +230,25 @@ func GetDirLicense(dir string) *License {
 }
 
 func findAndGetLicenseContent(dir string) []byte {
-	candidate := []string{filepath.Join(dir, "LICENSE"), filepath.Join(dir, "LICENSE.txt"), filepath.Join(dir, "LICENSE.md")}
-
-	search := true
+	p := FindLicenseFile(dir)
+	if p == "" {
+		return nil
+	}
+	b, _ := ioutil.ReadFile(p)
+	return b
+}
 
-	i := 0
-	var b []byte
-	b = nil
-	for search {
-		if e, _ := IsExistFile(candidate[i]); e {
-			search = false
-			b, _ = ioutil.ReadFile(candidate[i])
+//FindLicenseFile returns path of LICENSE file in dir. If dir has no LICENSE file, it returns empty string.
+func FindLicenseFile(dir string) string {
+	candidate := []string{filepath.Join(dir, "LICENSE"), filepath.Join(dir, "LICENSE.txt"), filepath.Join(dir, "LICENSE.md")}
 
-		} else {
-			i++
-			if !(i < len(candidate)) {
-				search = false
-			}
+	for _, c := range candidate {
+		if e, _ := IsExistFile(c); e {
+			return c
 		}
 	}
 
-	return b
+	return ""
 }
 
 //IsExistFilePath is validate whether val is exist filepath or not.
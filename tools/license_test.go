@@ -0,0 +1,36 @@
+package tools
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetDirLicense(t *testing.T) {
+	dir, err := ioutil.TempDir("", "liquid-license")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"mit", "apache", "bsd2", "bsd3", "gpl3"} {
+		expected := GetOSSLicense(name)
+		text, err := expected.LicenseText(&HeaderOptions{Author: "author", Year: 2019})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.WriteFile(filepath.Join(dir, "LICENSE"), []byte(text), 0644)
+		//detection must not depend on iteration order of OSSLicenses.
+		for i := 0; i < 10; i++ {
+			if got := GetDirLicense(dir); got != expected {
+				t.Fatalf("%s: expected %s, but got %+v", name, expected.Name, got)
+			}
+		}
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "LICENSE"), []byte("all rights reserved.\n"), 0644)
+	if got := GetDirLicense(dir); got == nil || got.Name != "custom" {
+		t.Errorf("unknown license must be custom license: %+v", got)
+	}
+}
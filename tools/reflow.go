@@ -0,0 +1,99 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tools
+
+import (
+	"regexp"
+	"strings"
+)
+
+//listItemPattern matches the beginning of list item such as "- ", "* ", "1. ", "2) " and "(a) ".
+var listItemPattern = regexp.MustCompile(`^([ \t]*)([-*+•]|[0-9]{1,3}[.)]|\([a-zA-Z0-9]{1,4}\)|[a-z][.)])[ \t]+`)
+
+//Reflow wraps paragraphs of s so that lines are not longer than width. Lines of a paragraph are joined before wrapping.
+//Blank lines, copyright lines and indented lines that are not in list items are kept as they are. List items begin new lines, and their wrapped lines are indented under the text of the item.
+//Words longer than width are not broken. If width is less than 1, s is returned as it is.
+func Reflow(s string, width int) string {
+	if width < 1 {
+		return s
+	}
+	lines := strings.Split(strings.Replace(s, "\r\n", "\n", -1), "\n")
+
+	var out, words []string
+	first, indent := "", ""
+	inItem := false
+	flush := func() {
+		if len(words) > 0 {
+			out = append(out, wrapWords(words, width, first, indent)...)
+		}
+		words = nil
+		first, indent = "", ""
+		inItem = false
+	}
+
+	for _, l := range lines {
+		l = strings.TrimRight(l, " \t")
+		t := strings.TrimSpace(l)
+		indented := len(l) > len(strings.TrimLeft(l, " \t"))
+		if t == "" || isCopyright([]byte(t)) {
+			flush()
+			out = append(out, l)
+			continue
+		}
+		if m := listItemPattern.FindStringSubmatch(l); m != nil {
+			flush()
+			first = m[1] + m[2] + " "
+			indent = strings.Repeat(" ", len([]rune(first)))
+			words = strings.Fields(l[len(m[0]):])
+			inItem = true
+			continue
+		}
+		if indented && !inItem {
+			flush()
+			out = append(out, l)
+			continue
+		}
+		words = append(words, strings.Fields(t)...)
+	}
+	flush()
+
+	return strings.Join(out, "\n")
+}
+
+//wrapWords joins words into lines not longer than width. The first line begins with first, and the other lines begin with indent.
+func wrapWords(words []string, width int, first, indent string) []string {
+	var lines []string
+	line := first
+	empty := true
+	for _, w := range words {
+		if !empty && len([]rune(line))+1+len([]rune(w)) > width {
+			lines = append(lines, line)
+			line = indent
+			empty = true
+		}
+		if !empty {
+			line += " "
+		}
+		line += w
+		empty = false
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,55 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReflow(t *testing.T) {
+	src := "Copyright (c) 2019 a very long name of author that is longer than width\n" +
+		"Redistribution and use in source and binary forms, with or without modification,\n" +
+		"are permitted provided that the following conditions are met:\n" +
+		"\n" +
+		"1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.\n" +
+		"2. Redistributions in binary form must reproduce the above copyright\n" +
+		"   notice.\n" +
+		"\n" +
+		"    http://www.apache.org/licenses/LICENSE-2.0\n" +
+		"- item\n"
+	expected := "Copyright (c) 2019 a very long name of author that is longer than width\n" +
+		"Redistribution and use in source and\n" +
+		"binary forms, with or without\n" +
+		"modification, are permitted provided\n" +
+		"that the following conditions are met:\n" +
+		"\n" +
+		"1. Redistributions of source code must\n" +
+		"   retain the above copyright notice,\n" +
+		"   this list of conditions and the\n" +
+		"   following disclaimer.\n" +
+		"2. Redistributions in binary form must\n" +
+		"   reproduce the above copyright notice.\n" +
+		"\n" +
+		"    http://www.apache.org/licenses/LICENSE-2.0\n" +
+		"- item\n"
+
+	got := Reflow(src, 40)
+	t.Log(got)
+	if got != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	l := &License{Name: "test", Header: src[strings.Index(src, "\n")+1:]}
+	var b strings.Builder
+	if err := l.WriteLicenseHeader(&b, &HeaderOptions{Author: "author", Year: 2019, Width: 40}); err != nil {
+		t.Fatal(err)
+	}
+	t.Log(b.String())
+	for _, line := range strings.Split(b.String(), "\n") {
+		if len(line) > 40 && !strings.Contains(line, "http") {
+			t.Errorf("line is longer than width: %q", line)
+		}
+	}
+	if status, _ := CheckHeader([]byte(b.String()+"\npackage a\n"), l, &HeaderOptions{Author: "author", Year: 2019}); status != HeaderCorrect {
+		t.Errorf("reflowed header must be correct, but got %s", status)
+	}
+}
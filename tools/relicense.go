@@ -0,0 +1,131 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tools
+
+import (
+	"regexp"
+	"strings"
+)
+
+//copyrightPlaceholder is placeholder of copyright line in license text of OSSLicenses.
+const copyrightPlaceholder = "{{ .copyright }}"
+
+//LicenseText returns text of LICENSE file of l with copyright line of opt.
+func (l *License) LicenseText(opt *HeaderOptions) (string, error) {
+	ct, err := opt.CopyrightLine()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(strings.Replace(l.Text, copyrightPlaceholder, ct, -1), "\r\n") + "\n", nil
+}
+
+//ReplaceLicenseName replaces names and SPDX identifier of license from in s with those of license to. It reports whether s contained them.
+//License without SPDX identifier such as custom license is not replaced, because its name is not written in documents.
+func ReplaceLicenseName(s string, from, to *License) (string, bool) {
+	if from.SPDX == "" {
+		return s, false
+	}
+	repl := make(map[string]string)
+	var names []string
+	for _, n := range [][2]string{{from.Name, to.Name}, {from.SPDX, to.SPDX}} {
+		if n[0] == "" || n[1] == "" {
+			continue
+		}
+		if _, ok := repl[n[0]]; !ok {
+			repl[n[0]] = n[1]
+			names = append(names, regexp.QuoteMeta(n[0]))
+		}
+	}
+	if len(names) == 0 {
+		return s, false
+	}
+
+	p := regexp.MustCompile(`\b(?:` + strings.Join(names, "|") + `)\b`)
+	found := false
+	s = p.ReplaceAllStringFunc(s, func(m string) string {
+		found = true
+		return repl[m]
+	})
+	return s, found
+}
+
+//readmeHeading matches markdown heading of license section of README.
+var readmeHeading = regexp.MustCompile(`^(#+)\s*(.*)$`)
+
+//UpdateReadmeLicense updates license section of README whose content is s from license from to license to. License section is markdown section whose heading is "License" or "Licence".
+//Names of license from in the section are replaced. If the section does not mention license from, its body is replaced with a sentence about license to.
+//If README has no license section, s is returned as it is.
+func UpdateReadmeLicense(s string, from, to *License, licenseFile string) string {
+	lines := strings.SplitAfter(s, "\n")
+	start, end, level := -1, len(lines), 0
+	for i, l := range lines {
+		m := readmeHeading.FindStringSubmatch(strings.TrimRight(l, "\r\n"))
+		if m == nil {
+			continue
+		}
+		if start < 0 {
+			t := strings.ToLower(strings.TrimSpace(strings.Trim(m[2], "#")))
+			if t == "license" || t == "licence" || t == "licensing" {
+				start, level = i, len(m[1])
+			}
+			continue
+		}
+		if len(m[1]) <= level {
+			end = i
+			break
+		}
+	}
+	if start < 0 {
+		return s
+	}
+
+	body := strings.Join(lines[start+1:end], "")
+	if nb, found := ReplaceLicenseName(body, from, to); found {
+		body = nb
+	} else {
+		body = "\n" + ReadmeLicenseSentence(to, licenseFile) + "\n"
+		if end < len(lines) {
+			body += "\n"
+		}
+	}
+	return strings.Join(lines[:start+1], "") + body + strings.Join(lines[end:], "")
+}
+
+//ReadmeLicenseSentence returns sentence of README that tells the project is licensed under l, whose text is in licenseFile.
+func ReadmeLicenseSentence(l *License, licenseFile string) string {
+	name := l.Name
+	if l.SPDX != "" {
+		name += " (" + l.SPDX + ")"
+	}
+	return "This project is licensed under the " + name + ". See [" + licenseFile + "](" + licenseFile + ") for details."
+}
+
+//NoticeText returns text of NOTICE file of project with copyright line of opt.
+func NoticeText(project string, opt *HeaderOptions) (string, error) {
+	ct, err := opt.CopyrightLine()
+	if err != nil {
+		return "", err
+	}
+	if project == "" {
+		return ct + "\n", nil
+	}
+	return project + "\n" + ct + "\n", nil
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\nten\n11\n12"
+	expected := "--- a/f\n+++ b/f\n" +
+		"@@ -1,3 +1,4 @@\n" +
+		"+0\n 1\n 2\n 3\n" +
+		"@@ -7,6 +8,6 @@\n" +
+		" 7\n 8\n 9\n-10\n+ten\n 11\n-12\n+12\n\\ No newline at end of file\n"
+
+	got := UnifiedDiff("a/f", "b/f", []byte(a), []byte(b))
+	t.Log(got)
+	if got != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+	if d := UnifiedDiff("a/f", "b/f", []byte(a), []byte(a)); d != "" {
+		t.Errorf("diff of equal contents must be empty: %s", d)
+	}
+}
+
+func TestUpdateReadmeLicense(t *testing.T) {
+	from := &License{Name: "MIT License", SPDX: "MIT"}
+	to := &License{Name: "Apache 2.0", SPDX: "Apache-2.0"}
+
+	readme := "# project\n\nSUBMIT issues.\n\n## License\n\nliquid is released under the MIT License (MIT).\n\n### Third party\n\nMIT\n\n## Author\n\nMIT is not replaced here.\n"
+	expected := "# project\n\nSUBMIT issues.\n\n## License\n\nliquid is released under the Apache 2.0 (Apache-2.0).\n\n### Third party\n\nApache-2.0\n\n## Author\n\nMIT is not replaced here.\n"
+	if got := UpdateReadmeLicense(readme, from, to, "LICENSE"); got != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	readme = "# project\n\n## Licence\n\nSee the file.\n"
+	expected = "# project\n\n## Licence\n\n" + ReadmeLicenseSentence(to, "LICENSE") + "\n"
+	if got := UpdateReadmeLicense(readme, from, to, "LICENSE"); got != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, got)
+	}
+
+	readme = "# project\n"
+	if got := UpdateReadmeLicense(readme, from, to, "LICENSE"); got != readme {
+		t.Errorf("README without license section must not be changed: %s", got)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+//Language is a language whose comment syntax liquid writes license header in.
+type Language struct {
+	Name string
+	//Extensions are file extensions of source files of the language.
+	Extensions []string
+}
+
+//Languages are languages that liquid processes. Comment styles of config and statistics of languages refer to it.
+var Languages = []*Language{
+	{Name: "Go", Extensions: []string{".go"}},
+}
+
+//LanguageOf returns language of the file fp from its extension. If the language is unknown, it returns nil.
+func LanguageOf(fp string) *Language {
+	ext := filepath.Ext(fp)
+	for _, l := range Languages {
+		for _, e := range l.Extensions {
+			if e == ext {
+				return l
+			}
+		}
+	}
+	return nil
+}
+
+//commentStyleNames are names of comment types that license header can be written in.
+var commentStyleNames = map[string]CommentType{
+	"line":  Lines,
+	"block": Wrap,
+	"doc":   DocWrap,
+}
+
+//ParseCommentStyle returns comment type of license header named name: "line" for "//" lines, "block" for "/* */" block or "doc" for "/** */" block.
+func ParseCommentStyle(name string) (CommentType, error) {
+	ct, ok := commentStyleNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Lines, fmt.Errorf("unknown comment style %q: it must be line, block or doc", name)
+	}
+	return ct, nil
+}
+
+func (ct CommentType) String() string {
+	switch ct {
+	case Wrap:
+		return "block"
+	case DocWrap:
+		return "doc"
+	}
+	return "line"
+}
+
+//blockCommentPrefix is prefix of lines in block commented by CommentifyBlock.
+const blockCommentPrefix = " * "
+
+//commentPrefixLen returns length of prefix of lines commented in ct.
+func commentPrefixLen(ct CommentType) int {
+	if ct == Lines {
+		return len(lineCommentPrefix)
+	}
+	return len(blockCommentPrefix)
+}
+
+//Commentify comments input in comment type ct.
+func Commentify(input string, ct CommentType) string {
+	if ct == Lines {
+		return CommentifyString(input)
+	}
+	return CommentifyBlock(input, ct == DocWrap)
+}
+
+//escapeBlockComment replaces "*/" in s with "* /" so that s does not close the block comment it is written in.
+func escapeBlockComment(s string) string {
+	return strings.ReplaceAll(s, "*/", "* /")
+}
+
+//CommentifyBlock comments input as a "/* */" block whose lines begin with " * ". If doc is true, the block begins with "/**".
+//"*/" in input is written as "* /" not to end the block early.
+func CommentifyBlock(input string, doc bool) string {
+	replacer := strings.NewReplacer("\r\n", "\n", "\r", "\n")
+	lines := strings.Split(escapeBlockComment(replacer.Replace(input)), "\n")
+
+	var sb strings.Builder
+	if doc {
+		sb.WriteString("/**\n")
+	} else {
+		sb.WriteString("/*\n")
+	}
+	for _, l := range lines {
+		if l == "" {
+			sb.WriteString(strings.TrimRight(blockCommentPrefix, " "))
+		} else {
+			sb.WriteString(blockCommentPrefix + l)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(" */")
+	return sb.String()
+}
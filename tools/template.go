@@ -0,0 +1,193 @@
+// Copyright (c) 2019 suquiya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tools
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+//DefaultHeaderTemplate is default template of license header. Header template is text/template whose data is HeaderData, and its output is commented for the target language.
+const DefaultHeaderTemplate = "{{.Copyright}}\n{{.License}}"
+
+//HeaderData is data of header template.
+type HeaderData struct {
+	//Copyright is copyright line formatted with CopyrightFormat.
+	Copyright string
+	//Year is copyright year, and YearRange is range from the first year of existing header to Year, like "2018-2019".
+	Year      string
+	YearRange string
+	Holder    string
+	Holders   []string
+	Email     string
+	//Project is name of the project. If it is not specified, the last element of Module is used.
+	Project string
+	//Module is go module path of the file.
+	Module string
+	//File is base name of the file.
+	File string
+	//License is header text of the license.
+	License     string
+	LicenseName string
+	SPDX        string
+	LicenseURL  string
+}
+
+//headerFuncs are helper functions of header template.
+var headerFuncs = template.FuncMap{
+	"comment": CommentifyString,
+	"wrap":    WrapText,
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"join": func(sep string, a []string) string {
+		return strings.Join(a, sep)
+	},
+}
+
+//ValidateHeaderTemplate reports error if tmpl is not valid header template.
+func ValidateHeaderTemplate(tmpl string) error {
+	opt := &HeaderOptions{Author: "author", Year: 2019, Template: tmpl}
+	l := &License{Name: "test", Header: "license"}
+	if _, err := opt.HeaderText(l); err != nil {
+		return err
+	}
+	if opt.expected(l) == nil {
+		return fmt.Errorf("header template must begin with copyright line")
+	}
+	return nil
+}
+
+//ForFile returns copy of opt for the file fp, with its file name, module path and comment style of its extension.
+func (opt *HeaderOptions) ForFile(fp string) *HeaderOptions {
+	o := *opt
+	o.File = filepath.Base(fp)
+	if ct, ok := opt.Styles[filepath.Ext(fp)]; ok {
+		o.Style = ct
+	}
+	if ap, err := filepath.Abs(fp); err == nil {
+		o.Module = ModulePath(filepath.Dir(ap))
+	}
+	return &o
+}
+
+//HeaderText returns uncommented license header of l rendered with header template of opt.
+func (opt *HeaderOptions) HeaderText(l *License) (string, error) {
+	ct, err := opt.CopyrightLine()
+	if err != nil {
+		return "", err
+	}
+	year := strconv.Itoa(opt.Year)
+	data := &HeaderData{
+		Copyright:   ct,
+		Year:        year,
+		YearRange:   year,
+		Holder:      opt.Author,
+		Holders:     SplitHolders(opt.Author),
+		Email:       opt.Email,
+		Project:     opt.Project,
+		Module:      opt.Module,
+		File:        opt.File,
+		License:     l.Header,
+		LicenseName: l.Name,
+		SPDX:        l.SPDX,
+		LicenseURL:  l.URL(),
+	}
+	if opt.FirstYear > 0 && opt.FirstYear < opt.Year {
+		data.YearRange = strconv.Itoa(opt.FirstYear) + "-" + year
+	}
+	if data.Project == "" && data.Module != "" {
+		data.Project = path.Base(data.Module)
+	}
+
+	tmpl := opt.Template
+	if tmpl == "" {
+		tmpl = DefaultHeaderTemplate
+	}
+	t, err := template.New("header").Funcs(headerFuncs).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	err = t.Execute(&sb, data)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(sb.String(), "\r\n"), nil
+}
+
+//SplitHolders splits copyright holders written like "A, B and C".
+func SplitHolders(holder string) []string {
+	var holders []string
+	for _, h := range strings.Split(holder, ",") {
+		for _, hh := range strings.Split(h, " and ") {
+			hh = strings.TrimSpace(hh)
+			if hh != "" {
+				holders = append(holders, hh)
+			}
+		}
+	}
+	return holders
+}
+
+//WrapText wraps paragraphs of s so that each line is not longer than width. It is wrap function of header template. See Reflow.
+func WrapText(width int, s string) string {
+	return Reflow(s, width)
+}
+
+//modulePaths caches module paths of directories.
+var modulePaths sync.Map
+
+//ModulePath returns go module path of directory dir, which is read from go.mod of dir or its parent directories. If dir is not in go module, it returns empty string.
+func ModulePath(dir string) string {
+	if m, ok := modulePaths.Load(dir); ok {
+		return m.(string)
+	}
+
+	m := ""
+	found := false
+	if f, err := os.Open(filepath.Join(dir, "go.mod")); err == nil {
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			l := strings.TrimSpace(sc.Text())
+			if strings.HasPrefix(l, "module") {
+				m = strings.Trim(strings.TrimSpace(strings.TrimPrefix(l, "module")), `"`)
+				found = true
+				break
+			}
+		}
+		f.Close()
+	}
+	if !found {
+		if parent := filepath.Dir(dir); parent != dir {
+			m = ModulePath(parent)
+		}
+	}
+
+	modulePaths.Store(dir, m)
+	return m
+}
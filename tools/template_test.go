@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeaderTemplate(t *testing.T) {
+	l := &License{Name: "MIT License", Header: "Licensed under the MIT license.", SPDX: "MIT"}
+	opt := &HeaderOptions{
+		Author:   "A, B and C",
+		Year:     2019,
+		Project:  "liquid",
+		File:     "a.go",
+		Template: "Copyright {{.YearRange}} {{join \" & \" .Holders}}\n\n{{upper .Project}} {{.File}}\nSPDX-License-Identifier: {{.SPDX}}\n{{.LicenseURL}}\n\n{{wrap 20 .License}}",
+	}
+	if err := ValidateHeaderTemplate(opt.Template); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := l.WriteLicenseHeader(&b, opt); err != nil {
+		t.Fatal(err)
+	}
+	t.Log(b.String())
+	expected := "// Copyright 2019 A & B & C\n// \n// LIQUID a.go\n// SPDX-License-Identifier: MIT\n// https://spdx.org/licenses/MIT.html\n// \n// Licensed under the\n// MIT license.\n"
+	if b.String() != expected {
+		t.Errorf("expected %q, but got %q", expected, b.String())
+	}
+
+	src := []byte(b.String() + "\npackage a\n")
+	if status, _ := CheckHeader(src, l, opt); status != HeaderCorrect {
+		t.Errorf("expected correct, but got %s", status)
+	}
+
+	old := ParseHeader([]byte("// Copyright 2017 A & B & C\n// old\n\npackage a\n"))
+	b.Reset()
+	l.WriteLicenseHeader(&b, opt.Inherit(old, l))
+	if !strings.HasPrefix(b.String(), "// Copyright 2017-2019 A & B & C\n") {
+		t.Errorf("first year of existing header is not kept: %s", b.String())
+	}
+
+	if err := ValidateHeaderTemplate("{{.License}}"); err == nil {
+		t.Error("template that does not begin with copyright line must be error")
+	}
+}